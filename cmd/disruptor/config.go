@@ -2,6 +2,9 @@ package main
 
 import (
 	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/voice"
 	"github.com/XanderD99/disruptor/pkg/logging"
 
 	"github.com/caarlos0/env/v11"
@@ -28,6 +31,21 @@ type Config struct {
 		// 🔗 Database connection string
 		DSN string `env:"DSN" default:"file::memory:?cache=shared"`
 	} `envPrefix:"DATABASE_"`
+
+	// ⏰ Configuration for the disruption scheduler
+	Scheduler scheduler.Config `envPrefix:"SCHEDULER_"`
+
+	// 🔊 Voice connection budget shared by scheduled disruptions and commands
+	Voice voice.Config `envPrefix:"VOICE_"`
+
+	// 🎶 Pacing of sound combos played in one voice connection
+	Combo handlers.ComboConfig `envPrefix:"COMBO_"`
+
+	// 🛠️ Configuration for the local admin endpoint
+	Admin struct {
+		// 🏠 Address the admin endpoint listens on, keep it local (empty to disable)
+		Addr string `env:"ADDR" default:"127.0.0.1:8081"`
+	} `envPrefix:"ADMIN_"`
 }
 
 func Load() (Config, error) {
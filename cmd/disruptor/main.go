@@ -6,6 +6,7 @@ import (
 	"log"
 	"log/slog"
 	"time"
+	_ "time/tzdata" // timezones for guild schedules, the final image has no zoneinfo
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/uptrace/bun"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/XanderD99/bunslog"
 
+	"github.com/XanderD99/disruptor/internal/admin"
 	"github.com/XanderD99/disruptor/internal/commands"
 	"github.com/XanderD99/disruptor/internal/disruptor"
 	"github.com/XanderD99/disruptor/internal/listeners"
@@ -22,6 +24,7 @@ import (
 	"github.com/XanderD99/disruptor/internal/otel"
 	"github.com/XanderD99/disruptor/internal/scheduler"
 	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/voice"
 	"github.com/XanderD99/disruptor/pkg/logging"
 	"github.com/XanderD99/disruptor/pkg/processes"
 )
@@ -59,33 +62,64 @@ func main() {
 	}
 	pm.AddProcessGroup(pg)
 
-	schedulerGroup, scheduleManager, err := initSchedulers(logger)
+	schedulerGroup, scheduleManager, dispatcher, err := initSchedulers(cfg, logger, database)
 	if err != nil {
 		log.Fatalf("Error initializing schedulers: %v", err)
 	}
 	pm.AddProcessGroup(schedulerGroup)
 
-	pg, err = initDiscordProcesses(cfg, logger, database, scheduleManager)
+	pg, err = initDiscordProcesses(cfg, logger, database, scheduleManager, dispatcher)
 	if err != nil {
 		log.Fatalf("Error initializing Discord processes: %v", err)
 	}
 
 	pm.AddProcessGroup(pg)
 
+	if cfg.Admin.Addr != "" {
+		pm.AddProcessGroup(initAdmin(cfg, logger, scheduleManager))
+	}
+
 	if err := pm.Run(); err != nil {
 		log.Fatalf("Error running process manager: %v", err)
 	}
 }
 
-func initSchedulers(logger *slog.Logger) (*processes.ProcessGroup, *scheduler.Manager, error) {
+func initSchedulers(cfg Config, logger *slog.Logger, db *bun.DB) (*processes.ProcessGroup, *scheduler.Manager, *scheduler.Dispatcher, error) {
 	group := processes.NewGroup("schedulers", time.Second*5)
 
 	// Initialize voice audio scheduler
-	voiceAudioScheduler := scheduler.NewManager(scheduler.WithLogger(logger))
+	opts := []scheduler.Option[scheduler.Manager]{
+		scheduler.WithLogger(logger),
+		scheduler.WithStore(scheduler.NewStore(db)),
+		scheduler.WithReconciler(cfg.Scheduler.ReconcileInterval, handlers.Reconciler(db)),
+		scheduler.WithSubscriber(scheduler.MetricsSubscriber()),
+	}
+	opts = append(opts, cfg.Scheduler.ToManagerOpts()...)
+
+	voiceAudioScheduler := scheduler.NewManager(opts...)
+	dispatcher := scheduler.NewDispatcher(db, scheduler.WithDispatcherLogger(logger))
+
+	if cfg.Scheduler.LeaseTTL > 0 {
+		// instances serving the same shards take turns, so overlapping deploys do not disrupt twice
+		name := fmt.Sprintf("schedulers:%v/%d", cfg.Disruptor.Sharding.ShardIDs, cfg.Disruptor.Sharding.ShardCount)
+		lease := scheduler.NewLease(db, name, scheduler.DefaultHolder(), cfg.Scheduler.LeaseTTL)
+		elector := scheduler.NewElector(lease, logger, voiceAudioScheduler, dispatcher)
+		group.AddProcessWithCtx("elector", elector.Start, false, elector.Stop)
+	} else {
+		group.AddProcessWithCtx("manager", voiceAudioScheduler.Start, false, voiceAudioScheduler.Stop)
+		group.AddProcessWithCtx("dispatcher", dispatcher.Start, false, dispatcher.Stop)
+	}
+
+	return group, voiceAudioScheduler, dispatcher, nil
+}
+
+func initAdmin(cfg Config, logger *slog.Logger, scheduleManager *scheduler.Manager) *processes.ProcessGroup {
+	group := processes.NewGroup("admin", time.Second*5)
 
-	group.AddProcessWithCtx("manager", voiceAudioScheduler.Start, false, voiceAudioScheduler.Stop)
+	server := admin.NewServer(cfg.Admin.Addr, logger, scheduleManager)
+	group.AddProcessWithCtx("server", server.Start, false, server.Stop)
 
-	return group, voiceAudioScheduler, nil
+	return group
 }
 
 func initDatabase(cfg Config, logger *slog.Logger) (*processes.ProcessGroup, *bun.DB, error) {
@@ -116,24 +150,40 @@ func initDatabase(cfg Config, logger *slog.Logger) (*processes.ProcessGroup, *bu
 	return group, database, nil
 }
 
-func initDiscordProcesses(cfg Config, logger *slog.Logger, db *bun.DB, scheduleManager *scheduler.Manager) (*processes.ProcessGroup, error) {
+func initDiscordProcesses(cfg Config, logger *slog.Logger, db *bun.DB, scheduleManager *scheduler.Manager, dispatcher *scheduler.Dispatcher) (*processes.ProcessGroup, error) {
 	group := processes.NewGroup("discord", time.Second*5)
 
+	limiter := voice.NewLimiter(cfg.Voice)
+
 	session, err := disruptor.New(
 		cfg.Disruptor,
+		disruptor.WithVoiceLimiter(limiter),
 		disruptor.WithMiddlewares(
 			middlewares.GoErrDefer,
 			middlewares.Otel,
 			middlewares.Logger,
 		),
 		disruptor.WithCommands(
-			commands.Play(),
+			commands.Play(db, limiter),
 			commands.Disconnect(),
 			commands.Invite(),
 			commands.Next(db, scheduleManager),
 			commands.Interval(db, scheduleManager),
+			commands.Schedule(db, scheduleManager, dispatcher),
+			commands.Quiet(db, scheduleManager),
+			commands.Chaos(db, scheduleManager),
+			commands.Pause(db),
+			commands.Alerts(db),
+			commands.Resume(db),
 			commands.Chance(db),
+			commands.Combo(db),
 			commands.Weight(db),
+			commands.Strategies(db),
+			commands.Triggers(db),
+			commands.Target(db),
+			commands.Consent(db),
+			commands.Entrance(db),
+			commands.Schedulers(scheduleManager, cfg.Disruptor.OwnerIDs),
 		),
 	)
 	if err != nil {
@@ -141,14 +191,26 @@ func initDiscordProcesses(cfg Config, logger *slog.Logger, db *bun.DB, scheduleM
 	}
 	group.AddProcessWithCtx("session", session.Open, false, session.Close)
 
+	handlers.RegisterStrategy(handlers.NewVoiceJoinStrategy(session, cfg.Combo))
+	handlers.RegisterStrategy(handlers.NewTextReactionStrategy(session))
+	handlers.RegisterStrategy(handlers.NewFollowTargetStrategy(session, db, cfg.Combo))
+	handlers.RegisterStrategy(handlers.NewSoundComboStrategy(session, cfg.Combo))
+
 	scheduleManager.RegisterBuilder(handlers.HandlerTypeRandomVoiceJoin, func(interval time.Duration) *scheduler.Scheduler {
-		return scheduler.NewScheduler(interval, handlers.NewRandomVoiceJoinHandler(session, db))
+		return scheduler.NewScheduler(interval, handlers.NewRandomVoiceJoinHandler(session, db), cfg.Scheduler.ToSchedulerOpts()...)
+	})
+	scheduleManager.RegisterBuilder(handlers.HandlerTypeChaosHour, func(interval time.Duration) *scheduler.Scheduler {
+		return scheduler.NewScheduler(interval, handlers.NewChaosHourHandler(session, db, scheduleManager), cfg.Scheduler.ToSchedulerOpts()...)
 	})
+	dispatcher.RegisterHandler(handlers.NewJobHandler(session))
+	dispatcher.RegisterFilter(session.OwnsGuild)
 
 	session.AddEventListeners(
 		bot.NewListenerFunc(listeners.GuildJoin(logger, db, scheduleManager)),
 		bot.NewListenerFunc(listeners.GuildLeave(logger, db, scheduleManager)),
 		bot.NewListenerFunc(listeners.GuildReady(logger, db, scheduleManager)),
+		bot.NewListenerFunc(listeners.VoiceTriggers(logger, handlers.NewVoiceTrigger(session, db, scheduleManager, cfg.Combo))),
+		bot.NewListenerFunc(listeners.Entrances(logger, session, db)),
 	)
 
 	return group, nil
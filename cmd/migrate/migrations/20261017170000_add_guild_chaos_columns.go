@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/models"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		return addColumns(ctx, db, (*models.Guild)(nil), "chaos_until", "chaos_duration", "chaos_interval", "chaos_interval_max", "chaos_chance", "chaos_cron")
+	}, func(ctx context.Context, db *bun.DB) error {
+		return dropColumns(ctx, db, (*models.Guild)(nil), "chaos_until", "chaos_duration", "chaos_interval", "chaos_interval_max", "chaos_chance", "chaos_cron")
+	})
+}
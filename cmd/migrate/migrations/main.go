@@ -1,6 +1,11 @@
 package migrations
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/migrate"
 )
 
@@ -11,3 +16,63 @@ func init() {
 		panic(err)
 	}
 }
+
+// addColumns adds columns of model to its table. Tables are created from the
+// current model, so columns that already exist are skipped.
+func addColumns(ctx context.Context, db *bun.DB, model any, columns ...string) error {
+	table := db.Table(reflect.TypeOf(model))
+
+	existing := make(map[string]bool)
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if existing[column] {
+			continue
+		}
+
+		field, err := table.Field(column)
+		if err != nil {
+			return err
+		}
+
+		definition := field.CreateTableSQLType
+		if field.NotNull {
+			definition += " NOT NULL"
+		}
+		if field.SQLDefault != "" {
+			definition += " DEFAULT " + field.SQLDefault
+		}
+
+		if _, err := db.NewAddColumn().Model(model).ColumnExpr("? "+definition, bun.Ident(column)).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to add column %s to %s: %w", column, table.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// dropColumns removes columns from the table of model.
+func dropColumns(ctx context.Context, db *bun.DB, model any, columns ...string) error {
+	for _, column := range columns {
+		if _, err := db.NewDropColumn().Model(model).Column(column).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to drop column %s: %w", column, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/XanderD99/disruptor/internal/scheduler"
+)
+
+// Server exposes the state of the bot over HTTP. It should only listen on a
+// local address, requests are not authenticated.
+type Server struct {
+	server *http.Server
+	logger *slog.Logger
+}
+
+func NewServer(addr string, logger *slog.Logger, manager *scheduler.Manager) *Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /schedulers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(manager.Snapshot()); err != nil {
+			logger.ErrorContext(r.Context(), "failed to encode schedulers", slog.Any("error", err))
+		}
+	})
+
+	return &Server{
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	s.logger.InfoContext(ctx, "starting admin server", slog.String("addr", s.server.Addr))
+
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to start admin server: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.server.Shutdown(ctx)
+}
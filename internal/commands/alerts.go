@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+type alerts struct {
+	db *bun.DB
+}
+
+func Alerts(db *bun.DB) disruptor.Command {
+	return alerts{db: db}
+}
+
+// Load implements disruptor.Command.
+func (a alerts) Load(r handler.Router) {
+	r.Route("/alerts", func(r handler.Router) {
+		r.SlashCommand("/set", a.set)
+		r.SlashCommand("/clear", a.clear)
+		r.SlashCommand("/status", a.status)
+	})
+}
+
+// Options implements disruptor.Command.
+func (a alerts) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "alerts",
+		Description:              "Get notified when disruptions keep failing",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "set",
+				Description: "Set the channel notified when disruptions are held back after failures",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionChannel{
+						Name:         "channel",
+						Description:  "Text channel to notify",
+						Required:     true,
+						ChannelTypes: []discord.ChannelType{discord.ChannelTypeGuildText},
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "clear",
+				Description: "Stop notifying about failing disruptions",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "status",
+				Description: "Show whether disruptions are held back after failures",
+			},
+		},
+	}
+}
+
+func (a alerts) set(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := a.guild(event)
+	if err != nil {
+		return err
+	}
+
+	guild.AlertChannelID = d.Channel("channel").ID
+	if _, err := a.db.NewUpdate().Model(&guild).Column("alert_channel_id").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update alert channel: %w", err)
+	}
+
+	return a.respond(event, fmt.Sprintf("Alerts are sent to <#%s>", guild.AlertChannelID), guild)
+}
+
+func (a alerts) clear(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := a.guild(event)
+	if err != nil {
+		return err
+	}
+
+	guild.AlertChannelID = 0
+	if _, err := a.db.NewUpdate().Model(&guild).Column("alert_channel_id").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update alert channel: %w", err)
+	}
+
+	return a.respond(event, "Alerts disabled", guild)
+}
+
+func (a alerts) status(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := a.guild(event)
+	if err != nil {
+		return err
+	}
+
+	description := "Alerts disabled"
+	if guild.AlertChannelID != 0 {
+		description = fmt.Sprintf("Alerts are sent to <#%s>", guild.AlertChannelID)
+	}
+
+	return a.respond(event, description, guild)
+}
+
+func (a alerts) guild(event *handler.CommandEvent) (models.Guild, error) {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return models.Guild{}, fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := a.db.NewSelect().Model(&guild).WherePK().Scan(event.Ctx); err != nil {
+		return models.Guild{}, fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	return guild, nil
+}
+
+// respond adds the state of the circuit of the guild to description.
+func (a alerts) respond(event *handler.CommandEvent, description string, guild models.Guild) error {
+	switch {
+	case guild.CircuitOpen(time.Now()):
+		description += fmt.Sprintf("\nDisruptions are held back until <t:%d:F> after %d failures, a `/play` that works resumes them", guild.BrokenUntil.Unix(), guild.Failures)
+	case guild.Failures > 0:
+		description += fmt.Sprintf("\nThe last %d disruptions failed", guild.Failures)
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+var _ disruptor.Command = (*alerts)(nil)
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// maxChoices is the most autocomplete choices Discord accepts.
+const maxChoices = 25
+
+// soundChoices suggests the soundboard sounds of a guild whose name contains query.
+func soundChoices(client *bot.Client, guildID snowflake.ID, query string) []discord.AutocompleteChoice {
+	query = strings.ToLower(query)
+
+	sounds := make([]discord.SoundboardSound, 0)
+	for sound := range client.Caches.GuildSoundboardSounds(guildID) {
+		if strings.Contains(strings.ToLower(sound.Name), query) {
+			sounds = append(sounds, sound)
+		}
+	}
+	slices.SortFunc(sounds, func(a, b discord.SoundboardSound) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	choices := make([]discord.AutocompleteChoice, 0, min(len(sounds), maxChoices))
+	for _, sound := range sounds[:min(len(sounds), maxChoices)] {
+		choices = append(choices, discord.AutocompleteChoiceString{Name: sound.Name, Value: sound.SoundID.String()})
+	}
+	return choices
+}
+
+// voiceChannelChoices suggests the voice channels of a guild whose name contains query.
+func voiceChannelChoices(client *bot.Client, guildID snowflake.ID, query string) []discord.AutocompleteChoice {
+	query = strings.ToLower(query)
+
+	channels := make([]discord.GuildChannel, 0)
+	for channel := range client.Caches.ChannelsForGuild(guildID) {
+		if channel.Type() == discord.ChannelTypeGuildVoice && strings.Contains(strings.ToLower(channel.Name()), query) {
+			channels = append(channels, channel)
+		}
+	}
+	slices.SortFunc(channels, func(a, b discord.GuildChannel) int {
+		return a.Position() - b.Position()
+	})
+
+	choices := make([]discord.AutocompleteChoice, 0, min(len(channels), maxChoices))
+	for _, channel := range channels[:min(len(channels), maxChoices)] {
+		choices = append(choices, discord.AutocompleteChoiceString{Name: channel.Name(), Value: channel.ID().String()})
+	}
+	return choices
+}
@@ -38,6 +38,14 @@ func (c chance) Options() discord.SlashCommandCreate {
 				Name:        "percentage",
 				Description: "Percentage chance of the event occurring (0-100)",
 			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "pity",
+				Description: "Percentage added to the chance after every miss until a hit, 0 disables pity (0-100)",
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "pity_cap",
+				Description: "Highest chance pity can raise the chance to (0-100, default 100)",
+			},
 		},
 	}
 }
@@ -57,13 +65,15 @@ func (c chance) handle(d discord.SlashCommandInteractionData, event *handler.Com
 	}
 
 	percentage, ok := d.OptInt("percentage")
-	if !ok {
+	pity, hasPity := d.OptInt("pity")
+	pityCap, hasPityCap := d.OptInt("pity_cap")
+	if !ok && !hasPity && !hasPityCap {
 		logger.DebugContext(event.Ctx, "displaying current chance percentage", "current_chance", guild.Chance)
 
 		embed := discord.NewEmbedBuilder()
 		embed.SetColor(util.RGBToInteger(255, 215, 0))
 
-		embed.SetDescription(fmt.Sprintf("Current chance percentage: %s", guild.Chance))
+		embed.SetDescription(fmt.Sprintf("Current chance percentage: %s%s", guild.Chance, describePity(guild)))
 
 		msg := discord.NewMessageUpdateBuilder().SetEmbeds((embed).Build()).Build()
 
@@ -74,16 +84,34 @@ func (c chance) handle(d discord.SlashCommandInteractionData, event *handler.Com
 		return nil
 	}
 
+	if !ok {
+		percentage = int(guild.Chance)
+	}
+
 	if percentage < 0 || percentage > 100 {
 		return fmt.Errorf("percentage must be between 0 and 100")
 	}
 
+	if hasPity {
+		if pity < 0 || pity > 100 {
+			return fmt.Errorf("pity must be between 0 and 100")
+		}
+		guild.Pity = models.Chance(pity)
+	}
+
+	if hasPityCap {
+		if pityCap < 0 || pityCap > 100 {
+			return fmt.Errorf("pity cap must be between 0 and 100")
+		}
+		guild.PityCap = models.Chance(pityCap)
+	}
+
 	oldChance := guild.Chance
 	guild.Chance = models.Chance(percentage)
 
 	logger.DebugContext(event.Ctx, "updating guild chance", "old_chance", oldChance, "new_chance", guild.Chance)
 
-	if _, err := c.db.NewUpdate().Model(&guild).WherePK().Exec(event.Ctx); err != nil {
+	if _, err := c.db.NewUpdate().Model(&guild).Column("chance", "pity", "pity_cap").WherePK().Exec(event.Ctx); err != nil {
 		return fmt.Errorf("failed to update guild chance: %w", err)
 	}
 
@@ -91,7 +119,7 @@ func (c chance) handle(d discord.SlashCommandInteractionData, event *handler.Com
 
 	embed := discord.NewEmbedBuilder()
 	embed.SetColor(util.RGBToInteger(255, 215, 0))
-	embed.SetDescription(fmt.Sprintf("Chance set to: %d%%", percentage))
+	embed.SetDescription(fmt.Sprintf("Chance set to: %d%%%s", percentage, describePity(guild)))
 	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
 	if _, err := event.UpdateInteractionResponse(msg); err != nil {
 		return fmt.Errorf("failed to update interaction response: %w", err)
@@ -100,4 +128,18 @@ func (c chance) handle(d discord.SlashCommandInteractionData, event *handler.Com
 	return nil
 }
 
+// describePity explains how pity currently raises the chance of a guild.
+func describePity(guild models.Guild) string {
+	if guild.Pity <= 0 {
+		return ""
+	}
+
+	limit := guild.PityCap
+	if limit <= 0 {
+		limit = 100
+	}
+
+	return fmt.Sprintf("\nPity: +%s per miss up to %s, %d misses in a row, effective chance %s", guild.Pity, limit, guild.Misses, guild.EffectiveChance())
+}
+
 var _ disruptor.Command = (*chance)(nil)
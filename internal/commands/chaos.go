@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/pkg/logging"
+)
+
+type chaos struct {
+	manager *scheduler.Manager
+	db      *bun.DB
+}
+
+func Chaos(db *bun.DB, manager *scheduler.Manager) disruptor.Command {
+	return chaos{manager: manager, db: db}
+}
+
+// Load implements disruptor.Command.
+func (c chaos) Load(r handler.Router) {
+	r.Route("/chaos", func(r handler.Router) {
+		r.SlashCommand("/start", c.start)
+		r.SlashCommand("/stop", c.stop)
+		r.SlashCommand("/schedule", c.schedule)
+		r.SlashCommand("/unschedule", c.unschedule)
+	})
+}
+
+// chaosSettings are the options shared by the subcommands that start chaos hours.
+var chaosSettings = []discord.ApplicationCommandOption{
+	discord.ApplicationCommandOptionString{
+		Name:        "duration",
+		Description: "How long chaos hour lasts (1m-24h, default 1h)",
+	},
+	discord.ApplicationCommandOptionString{
+		Name:        "interval",
+		Description: "Delay between sounds during chaos hour, fixed (3m) or a range (2m-5m, default)",
+	},
+	discord.ApplicationCommandOptionInt{
+		Name:        "chance",
+		Description: "Chance of a sound during chaos hour (1-100, default 100)",
+	},
+}
+
+// Options implements disruptor.Command.
+func (c chaos) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "chaos",
+		Description:              "Disrupt much more often for a limited time",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "start",
+				Description: "Start a chaos hour now",
+				Options:     chaosSettings,
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "stop",
+				Description: "End the running chaos hour and go back to the normal settings",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "schedule",
+				Description: "Start chaos hours on a cron schedule",
+				Options: append([]discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:        "expression",
+						Description: "Cron expression in the timezone of the schedule. example: \"0 20 * * fri\"",
+						Required:    true,
+					},
+				}, chaosSettings...),
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "unschedule",
+				Description: "Stop starting chaos hours on a schedule",
+			},
+		},
+	}
+}
+
+func (c chaos) start(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	// Get logger from context (added by the middleware)
+	logger := logging.FromContext(event.Ctx)
+
+	guild, err := c.guild(event)
+	if err != nil {
+		return err
+	}
+
+	if err := applyChaosSettings(d, &guild); err != nil {
+		return err
+	}
+
+	if _, err := c.db.NewUpdate().Model(&guild).Column(chaosColumns...).WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update chaos hour: %w", err)
+	}
+
+	if err := handlers.StartChaos(event.Ctx, c.db, c.manager, &guild, c.manager.Now()); err != nil {
+		return err
+	}
+
+	logger.DebugContext(event.Ctx, "started chaos hour", "until", guild.ChaosUntil)
+
+	return c.respond(event, "Chaos hour started!", guild)
+}
+
+func (c chaos) stop(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := c.guild(event)
+	if err != nil {
+		return err
+	}
+
+	if !guild.Chaos(c.manager.Now()) {
+		return c.respond(event, "There is no chaos hour running", guild)
+	}
+
+	guild.ChaosUntil = time.Time{}
+	if _, err := c.db.NewUpdate().Model(&guild).Column("chaos_until").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to stop chaos hour: %w", err)
+	}
+
+	if err := handlers.Schedule(event.Ctx, c.manager, guild); err != nil {
+		return fmt.Errorf("failed to add guild to voice audio scheduler manager: %w", err)
+	}
+
+	return c.respond(event, "Chaos hour stopped, back to normal", guild)
+}
+
+func (c chaos) schedule(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := c.guild(event)
+	if err != nil {
+		return err
+	}
+
+	if err := applyChaosSettings(d, &guild); err != nil {
+		return err
+	}
+
+	guild.ChaosCron = strings.TrimSpace(d.String("expression"))
+	if err := handlers.ScheduleChaos(event.Ctx, c.manager, guild); err != nil {
+		return err
+	}
+
+	if _, err := c.db.NewUpdate().Model(&guild).Column(slices.Concat(chaosColumns, []string{"chaos_cron"})...).WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update chaos schedule: %w", err)
+	}
+
+	return c.respond(event, "Chaos schedule set", guild)
+}
+
+func (c chaos) unschedule(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := c.guild(event)
+	if err != nil {
+		return err
+	}
+
+	guild.ChaosCron = ""
+	if _, err := c.db.NewUpdate().Model(&guild).Column("chaos_cron").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update chaos schedule: %w", err)
+	}
+
+	if err := handlers.ScheduleChaos(event.Ctx, c.manager, guild); err != nil {
+		return err
+	}
+
+	return c.respond(event, "Chaos schedule removed", guild)
+}
+
+func (c chaos) guild(event *handler.CommandEvent) (models.Guild, error) {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return models.Guild{}, fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := c.db.NewSelect().Model(&guild).WherePK().Scan(event.Ctx); err != nil {
+		return models.Guild{}, fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	return guild, nil
+}
+
+func (c chaos) respond(event *handler.CommandEvent, description string, guild models.Guild) error {
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description + describeChaos(guild, c.manager.Now()))
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+// chaosColumns are the guild columns applyChaosSettings changes.
+var chaosColumns = []string{"chaos_duration", "chaos_interval", "chaos_interval_max", "chaos_chance"}
+
+// applyChaosSettings stores the chaos hour options that were given in guild.
+func applyChaosSettings(d discord.SlashCommandInteractionData, guild *models.Guild) error {
+	if value, ok := d.OptString("duration"); ok {
+		duration, err := parseIntervalDuration(value)
+		if err != nil {
+			return err
+		}
+		guild.ChaosDuration = duration
+	}
+
+	if value, ok := d.OptString("interval"); ok {
+		distribution, minimum, maximum, err := parseInterval(value)
+		if err != nil {
+			return err
+		}
+		if distribution == models.DistributionExponential {
+			return fmt.Errorf("chaos hour needs a fixed delay (3m) or a range (2m-5m)")
+		}
+		guild.ChaosInterval, guild.ChaosIntervalMax = minimum, maximum
+	}
+
+	if value, ok := d.OptInt("chance"); ok {
+		if value < 1 || value > 100 {
+			return fmt.Errorf("chance must be between 1 and 100")
+		}
+		guild.ChaosChance = models.Chance(value)
+	}
+
+	return nil
+}
+
+func describeChaos(guild models.Guild, now time.Time) string {
+	minimum, maximum := guild.ChaosRange()
+	delay := minimum.String()
+	if maximum > minimum {
+		delay = fmt.Sprintf("%s-%s", minimum, maximum)
+	}
+
+	description := fmt.Sprintf("\nChaos hour: every %s at %s for %s", delay, guild.ChaosOdds(), guild.ChaosLength())
+	if guild.Chaos(now) {
+		description += fmt.Sprintf("\nRunning until <t:%d:F> <t:%d:R>", guild.ChaosUntil.Unix(), guild.ChaosUntil.Unix())
+	}
+	if guild.ChaosCron != "" {
+		description += fmt.Sprintf("\nStarts on schedule: `%s` (%s)", guild.ChaosCron, timezoneName(guild))
+	}
+	return description
+}
+
+var _ disruptor.Command = (*chaos)(nil)
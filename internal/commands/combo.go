@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+type combo struct {
+	db *bun.DB
+}
+
+func Combo(db *bun.DB) disruptor.Command {
+	return combo{db: db}
+}
+
+// Load implements disruptor.Command.
+func (c combo) Load(r handler.Router) {
+	r.SlashCommand("/combo", c.handle)
+}
+
+var (
+	minComboChance = 0
+	maxComboChance = 100
+	minComboLength = 1
+	maxComboLength = models.MaxComboLength
+)
+
+// Options implements disruptor.Command.
+func (c combo) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "combo",
+		Description:              "Play several sounds in a row in one visit",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionInt{
+				Name:        "chance",
+				Description: "Chance a voice disruption plays a combo instead of one sound (0-100, 0 disables combos)",
+				MinValue:    &minComboChance,
+				MaxValue:    &maxComboChance,
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "min",
+				Description: "Fewest sounds in a combo (default 2)",
+				MinValue:    &minComboLength,
+				MaxValue:    &maxComboLength,
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "max",
+				Description: "Most sounds in a combo (default 4)",
+				MinValue:    &minComboLength,
+				MaxValue:    &maxComboLength,
+			},
+		},
+	}
+}
+
+func (c combo) handle(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := c.db.NewSelect().Model(&guild).WherePK().Scan(event.Ctx); err != nil {
+		return fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	chance, hasChance := d.OptInt("chance")
+	minimum, hasMin := d.OptInt("min")
+	maximum, hasMax := d.OptInt("max")
+
+	if hasChance || hasMin || hasMax {
+		currentMin, currentMax := guild.ComboRange()
+		if !hasMin {
+			minimum = currentMin
+		}
+		if !hasMax {
+			maximum = max(currentMax, minimum)
+		}
+		if maximum < minimum {
+			return fmt.Errorf("max must be at least min")
+		}
+
+		if hasChance {
+			guild.ComboChance = models.Chance(chance)
+		}
+		guild.ComboMin, guild.ComboMax = minimum, maximum
+
+		if _, err := c.db.NewUpdate().Model(&guild).Column("combo_chance", "combo_min", "combo_max").WherePK().Exec(event.Ctx); err != nil {
+			return fmt.Errorf("failed to update combo settings: %w", err)
+		}
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(describeCombo(guild))
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+// describeCombo explains how often and how long the combos of a guild are.
+func describeCombo(guild models.Guild) string {
+	minimum, maximum := guild.ComboRange()
+
+	length := fmt.Sprintf("%d-%d sounds", minimum, maximum)
+	if minimum == maximum {
+		length = fmt.Sprintf("%d sounds", minimum)
+	}
+
+	if guild.ComboChance <= 0 {
+		return fmt.Sprintf("Voice disruptions play one sound, combos of %s only play with the `%s` strategy", length, handlers.StrategySoundCombo)
+	}
+	return fmt.Sprintf("%s of voice disruptions play a combo of %s", guild.ComboChance, length)
+}
+
+var _ disruptor.Command = (*combo)(nil)
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+type consent struct {
+	db *bun.DB
+}
+
+// Consent lets members agree to, or refuse, being followed with /target.
+func Consent(db *bun.DB) disruptor.Command {
+	return consent{db: db}
+}
+
+// Load implements disruptor.Command.
+func (c consent) Load(r handler.Router) {
+	r.Route("/consent", func(r handler.Router) {
+		r.SlashCommand("/give", c.give)
+		r.SlashCommand("/revoke", c.revoke)
+		r.SlashCommand("/status", c.status)
+	})
+}
+
+// Options implements disruptor.Command.
+func (c consent) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:        "consent",
+		Description: "Decide whether admins can target you with /target",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "give",
+				Description: "Allow the bot to follow you into voice channels",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "revoke",
+				Description: "Stop the bot from following you, it stops right away when you are targeted",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "status",
+				Description: "Show whether you can be targeted",
+			},
+		},
+	}
+}
+
+func (c consent) give(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	return c.update(event, true)
+}
+
+func (c consent) revoke(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	return c.update(event, false)
+}
+
+func (c consent) update(event *handler.CommandEvent, targetable bool) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	member := models.Member{GuildID: *guildID, UserID: event.User().ID, Targetable: targetable}
+	if _, err := c.db.NewInsert().Model(&member).On("CONFLICT (guild_id, user_id) DO UPDATE").Set("targetable = EXCLUDED.targetable").Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update consent: %w", err)
+	}
+
+	if !targetable {
+		if _, err := c.db.NewUpdate().Model((*models.Guild)(nil)).Set("target_id = NULL").Where("id = ? AND target_id = ?", member.GuildID, member.UserID).Exec(event.Ctx); err != nil {
+			return fmt.Errorf("failed to clear target: %w", err)
+		}
+	}
+
+	return c.respond(event, targetable)
+}
+
+func (c consent) status(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	targetable, err := handlers.Targetable(event.Ctx, c.db, *guildID, event.User().ID)
+	if err != nil {
+		return err
+	}
+
+	return c.respond(event, targetable)
+}
+
+func (c consent) respond(event *handler.CommandEvent, targetable bool) error {
+	description := "You can not be targeted"
+	if targetable {
+		description = "You can be targeted, the bot may follow you into voice channels"
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+var _ disruptor.Command = (*consent)(nil)
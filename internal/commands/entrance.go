@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+var (
+	minEntranceMembers = 1
+	maxEntranceMembers = 99
+)
+
+type entrance struct {
+	db *bun.DB
+}
+
+// Entrance lets members pick a sound that plays when they join a voice channel.
+func Entrance(db *bun.DB) disruptor.Command {
+	return entrance{db: db}
+}
+
+// Load implements disruptor.Command.
+func (e entrance) Load(r handler.Router) {
+	r.Route("/entrance", func(r handler.Router) {
+		r.SlashCommand("/set", e.set)
+		r.SlashCommand("/clear", e.clear)
+		r.SlashCommand("/show", e.show)
+		r.SlashCommand("/settings", e.settings)
+		r.Autocomplete("/set", e.autocomplete)
+	})
+}
+
+// Options implements disruptor.Command.
+func (e entrance) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:        "entrance",
+		Description: "Pick a sound that plays when you join a voice channel",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "set",
+				Description: "Pick your entrance sound",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:         "sound",
+						Description:  "The soundboard sound to play",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "clear",
+				Description: "Stop playing your entrance sound",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "show",
+				Description: "Show your entrance sound and the rules of the server",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "settings",
+				Description: "Turn entrance sounds on or off for the server (Manage Server only)",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionBool{
+						Name:        "enabled",
+						Description: "Whether entrance sounds play in the server",
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "cooldown",
+						Description: "How long before the sound of a member plays again. example: 10m, 1h (default 10m)",
+					},
+					discord.ApplicationCommandOptionInt{
+						Name:        "members",
+						Description: "Members a channel must have, the joining member included (default 2)",
+						MinValue:    &minEntranceMembers,
+						MaxValue:    &maxEntranceMembers,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (e entrance) set(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := e.guild(event)
+	if err != nil {
+		return err
+	}
+
+	soundID, err := snowflake.Parse(d.String("sound"))
+	if err != nil {
+		return fmt.Errorf("pick a sound from the list")
+	}
+	sound, ok := event.Client().Caches.GuildSoundboardSound(guild.ID, soundID)
+	if !ok {
+		return fmt.Errorf("pick a sound from the list")
+	}
+
+	entrance := models.Entrance{GuildID: guild.ID, UserID: event.User().ID, SoundID: sound.SoundID}
+	if _, err := e.db.NewInsert().Model(&entrance).On("CONFLICT (guild_id, user_id) DO UPDATE").Set("sound_id = EXCLUDED.sound_id").Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to set entrance sound: %w", err)
+	}
+
+	return e.respond(event, fmt.Sprintf("Your entrance sound is now **%s**", sound.Name), guild)
+}
+
+func (e entrance) clear(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := e.guild(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.db.NewDelete().Model(&models.Entrance{GuildID: guild.ID, UserID: event.User().ID}).WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to clear entrance sound: %w", err)
+	}
+
+	return e.respond(event, "You no longer have an entrance sound", guild)
+}
+
+func (e entrance) show(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := e.guild(event)
+	if err != nil {
+		return err
+	}
+
+	entrance := models.Entrance{GuildID: guild.ID, UserID: event.User().ID}
+	if err := e.db.NewSelect().Model(&entrance).WherePK().Scan(event.Ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.respond(event, "You have no entrance sound, pick one with `/entrance set`", guild)
+		}
+		return fmt.Errorf("failed to find entrance sound: %w", err)
+	}
+
+	sound, ok := event.Client().Caches.GuildSoundboardSound(guild.ID, entrance.SoundID)
+	if !ok {
+		return e.respond(event, "Your entrance sound was removed from the soundboard, pick another one with `/entrance set`", guild)
+	}
+
+	return e.respond(event, fmt.Sprintf("Your entrance sound is **%s**", sound.Name), guild)
+}
+
+func (e entrance) settings(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := e.guild(event)
+	if err != nil {
+		return err
+	}
+
+	if member := event.Member(); member == nil || !member.Permissions.Has(discord.PermissionManageGuild) {
+		return fmt.Errorf("you need the `MANAGE_SERVER` permission to change the entrance settings")
+	}
+
+	columns := make([]string, 0, 3)
+	if enabled, ok := d.OptBool("enabled"); ok {
+		guild.Entrances = enabled
+		columns = append(columns, "entrances")
+	}
+	if value, ok := d.OptString("cooldown"); ok {
+		if guild.EntranceCooldown, err = parseIntervalDuration(value); err != nil {
+			return err
+		}
+		columns = append(columns, "entrance_cooldown")
+	}
+	if members, ok := d.OptInt("members"); ok {
+		guild.EntranceMembers = members
+		columns = append(columns, "entrance_members")
+	}
+
+	if len(columns) > 0 {
+		if _, err := e.db.NewUpdate().Model(&guild).Column(columns...).WherePK().Exec(event.Ctx); err != nil {
+			return fmt.Errorf("failed to update entrance settings: %w", err)
+		}
+	}
+
+	return e.respond(event, "", guild)
+}
+
+func (e entrance) autocomplete(event *handler.AutocompleteEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return event.AutocompleteResult(nil)
+	}
+
+	return event.AutocompleteResult(soundChoices(event.Client(), *guildID, event.Data.String("sound")))
+}
+
+func (e entrance) guild(event *handler.CommandEvent) (models.Guild, error) {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return models.Guild{}, fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := e.db.NewSelect().Model(&guild).WherePK().Scan(event.Ctx); err != nil {
+		return models.Guild{}, fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	return guild, nil
+}
+
+// respond shows description along with the entrance rules of the guild.
+func (e entrance) respond(event *handler.CommandEvent, description string, guild models.Guild) error {
+	cooldown, members := guild.EntranceRules()
+
+	rules := fmt.Sprintf("Entrance sounds play when a channel has at least %d members, at most once every %s per member", members, cooldown)
+	if !guild.Entrances {
+		rules = "Entrance sounds are turned off in this server, an admin can turn them on with `/entrance settings enabled:True`"
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+	embed.AddField("Rules", rules, false)
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+var _ disruptor.Command = (*entrance)(nil)
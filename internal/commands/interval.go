@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/disgoorg/disgo/discord"
@@ -40,7 +41,7 @@ func (i interval) Options() discord.SlashCommandCreate {
 		Options: []discord.ApplicationCommandOption{
 			discord.ApplicationCommandOptionString{
 				Name:        "duration",
-				Description: "Duration of the interval, Valid time units are \"s\", \"m\", \"h\". example: 300m, 1.5h or 2h45m",
+				Description: "Fixed (1.5h), random range (30m-2h) or random with a mean (~1h). Valid units are \"s\", \"m\", \"h\"",
 			},
 		},
 	}
@@ -68,7 +69,11 @@ func (i interval) handle(d discord.SlashCommandInteractionData, event *handler.C
 		embed := discord.NewEmbedBuilder()
 		embed.SetColor(util.RGBToInteger(255, 215, 0))
 
-		embed.SetDescription(fmt.Sprintf("Current interval: %s", guild.Interval))
+		if guild.Cron != "" {
+			embed.SetDescription(fmt.Sprintf("Current schedule: `%s`, set an interval to replace it", guild.Cron))
+		} else {
+			embed.SetDescription(fmt.Sprintf("Current interval: %s", formatInterval(guild)))
+		}
 
 		msg := discord.NewMessageUpdateBuilder().SetEmbeds((embed).Build()).Build()
 
@@ -80,26 +85,20 @@ func (i interval) handle(d discord.SlashCommandInteractionData, event *handler.C
 	}
 
 	var err error
-	guild.Interval, err = time.ParseDuration(intervalString)
+	guild.Distribution, guild.Interval, guild.IntervalMax, err = parseInterval(intervalString)
 	if err != nil {
-		return fmt.Errorf("failed to parse duration: %w", err)
-	}
-
-	if guild.Interval < (time.Minute) {
-		return fmt.Errorf("invalid duration: %s, must be greater or equal than 1m", intervalString)
+		return err
 	}
 
-	if guild.Interval > (time.Hour * 24) {
-		return fmt.Errorf("invalid duration: %s, must be less than 24h", intervalString)
-	}
+	guild.Cron = "" // an interval replaces any cron schedule
 
 	logger.DebugContext(event.Ctx, "updating guild interval", "new_interval", guild.Interval)
 
-	if _, err := i.db.NewUpdate().Model(&guild).WherePK().Exec(event.Ctx); err != nil {
+	if _, err := i.db.NewUpdate().Model(&guild).Column("distribution", "interval", "interval_max", "cron").WherePK().Exec(event.Ctx); err != nil {
 		return fmt.Errorf("failed to update guild interval: %w", err)
 	}
 
-	if err := i.manager.AddScheduler(handlers.HandlerTypeRandomVoiceJoin, guild.Interval); err != nil {
+	if err := handlers.Schedule(event.Ctx, i.manager, guild); err != nil {
 		return fmt.Errorf("failed to add guild to voice audio scheduler manager: %w", err)
 	}
 
@@ -107,7 +106,7 @@ func (i interval) handle(d discord.SlashCommandInteractionData, event *handler.C
 
 	embed := discord.NewEmbedBuilder()
 	embed.SetColor(util.RGBToInteger(255, 215, 0))
-	embed.SetDescription(fmt.Sprintf("Interval set to: %s", guild.Interval))
+	embed.SetDescription(fmt.Sprintf("Interval set to: %s", formatInterval(guild)))
 	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
 	if _, err := event.UpdateInteractionResponse(msg); err != nil {
 		return fmt.Errorf("failed to update interaction response: %w", err)
@@ -116,4 +115,61 @@ func (i interval) handle(d discord.SlashCommandInteractionData, event *handler.C
 	return nil
 }
 
+// parseInterval parses a fixed duration (1h), a uniform range (30m-2h) or an
+// exponential mean (~1h).
+func parseInterval(s string) (models.Distribution, time.Duration, time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	if mean, ok := strings.CutPrefix(s, "~"); ok {
+		d, err := parseIntervalDuration(mean)
+		return models.DistributionExponential, d, 0, err
+	}
+
+	if lo, hi, ok := strings.Cut(s, "-"); ok {
+		minimum, err := parseIntervalDuration(lo)
+		if err != nil {
+			return "", 0, 0, err
+		}
+		maximum, err := parseIntervalDuration(hi)
+		if err != nil {
+			return "", 0, 0, err
+		}
+		if maximum <= minimum {
+			return "", 0, 0, fmt.Errorf("invalid range: %s, the maximum must be greater than the minimum", s)
+		}
+		return models.DistributionUniform, minimum, maximum, nil
+	}
+
+	d, err := parseIntervalDuration(s)
+	return models.DistributionFixed, d, 0, err
+}
+
+func parseIntervalDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration: %w", err)
+	}
+
+	if d < (time.Minute) {
+		return 0, fmt.Errorf("invalid duration: %s, must be greater or equal than 1m", s)
+	}
+
+	if d > (time.Hour * 24) {
+		return 0, fmt.Errorf("invalid duration: %s, must be less than 24h", s)
+	}
+
+	return d, nil
+}
+
+func formatInterval(guild models.Guild) string {
+	switch guild.Distribution {
+	case models.DistributionUniform:
+		return fmt.Sprintf("random between %s and %s", guild.Interval, guild.IntervalMax)
+	case models.DistributionExponential:
+		return fmt.Sprintf("random, %s on average", guild.Interval)
+	default:
+		return guild.Interval.String()
+	}
+}
+
 var _ disruptor.Command = (*play)(nil)
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
@@ -46,23 +47,33 @@ func (n next) handle(_ discord.SlashCommandInteractionData, e *handler.CommandEv
 	}
 
 	guild := models.Guild{ID: *guildID}
-	if err := n.db.NewSelect().Model(&guild).WherePK().Scan(e.Ctx, &guild); err != nil {
+	if err := n.db.NewSelect().Model(&guild).WherePK().Relation("QuietHours").Scan(e.Ctx); err != nil {
 		return fmt.Errorf("failed to find guild: %w", err)
 	}
 
-	logger.DebugContext(e.Ctx, "looking up scheduler for guild", "interval", guild.Interval)
+	logger.DebugContext(e.Ctx, "looking up next fire time for guild", "interval", guild.Interval)
 
-	group, ok := n.manager.GetScheduler(handlers.HandlerTypeRandomVoiceJoin, guild.Interval)
+	interval, ok := handlers.NextFire(n.manager, guild)
 	if !ok {
-		logger.WarnContext(e.Ctx, "no scheduler found for interval", "interval", guild.Interval)
-		return fmt.Errorf("no scheduler found")
+		logger.WarnContext(e.Ctx, "no fire time outside quiet hours found", "interval", guild.Interval)
+		return fmt.Errorf("no upcoming disruption found")
 	}
 
-	interval := group.NextInterval()
 	logger.DebugContext(e.Ctx, "retrieved next interval time", "next_time", interval)
 
+	description := fmt.Sprintf("Next interval time: <t:%d:F> <t:%d:R>", interval.Unix(), interval.Unix())
+	if guild.CircuitOpen(time.Now()) {
+		description += fmt.Sprintf("\nHeld back until <t:%d:F> after %d failed disruptions", guild.BrokenUntil.Unix(), guild.Failures)
+	}
+	if guild.Paused(time.Now()) {
+		description += fmt.Sprintf("\nPaused until <t:%d:F> by <@%s>", guild.PausedUntil.Unix(), guild.PausedBy)
+	}
+	if timing, ok := n.manager.Timing(handlers.HandlerTypeRandomVoiceJoin, guild.ID); ok {
+		description += fmt.Sprintf("\nDrawn from: `%s`", timing)
+	}
+
 	embed := discord.Embed{
-		Description: fmt.Sprintf("Next interval time: <t:%d:F> <t:%d:R>", interval.Unix(), interval.Unix()),
+		Description: description,
 		Color:       0x5c5fea, // PrimaryColor
 	}
 
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// owners decides who may use owner-only commands. Without configured IDs the
+// owner of the application, or the members of its team, are looked up once.
+type owners struct {
+	mu     sync.Mutex
+	ids    []snowflake.ID
+	loaded bool // the ids are configured or were looked up, they may be empty
+}
+
+func newOwners(ids []snowflake.ID) *owners {
+	return &owners{ids: ids, loaded: len(ids) > 0}
+}
+
+// check returns an error when the user of the event is not an owner.
+func (o *owners) check(event *handler.CommandEvent) error {
+	ids, err := o.load(event)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(ids, event.User().ID) {
+		return fmt.Errorf("this command can only be used by the bot owners")
+	}
+	return nil
+}
+
+func (o *owners) load(event *handler.CommandEvent) ([]snowflake.ID, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.loaded {
+		return o.ids, nil
+	}
+
+	app, err := event.Client().Rest.GetCurrentApplication(rest.WithCtx(event.Ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application owners: %w", err)
+	}
+
+	switch {
+	case app.Team != nil:
+		for _, member := range app.Team.Members {
+			o.ids = append(o.ids, member.User.ID)
+		}
+	case app.Owner != nil:
+		o.ids = append(o.ids, app.Owner.ID)
+	}
+	o.loaded = true
+
+	return o.ids, nil
+}
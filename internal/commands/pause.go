@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/pkg/logging"
+)
+
+const (
+	defaultPause = time.Hour
+	maxPause     = 30 * 24 * time.Hour
+)
+
+type pause struct {
+	db *bun.DB
+}
+
+func Pause(db *bun.DB) disruptor.Command {
+	return pause{db: db}
+}
+
+// Load implements disruptor.Command.
+func (p pause) Load(r handler.Router) {
+	r.SlashCommand("/pause", p.handle)
+}
+
+// Options implements disruptor.Command.
+func (p pause) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "pause",
+		Description:              "Pause disruptions for a while without changing the settings",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{
+				Name:        "duration",
+				Description: "How long to pause, from 1m up to 30d. example: 2h, 3d (default 1h)",
+			},
+		},
+	}
+}
+
+func (p pause) handle(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	// Get logger from context (added by the middleware)
+	logger := logging.FromContext(event.Ctx)
+
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	duration := defaultPause
+	if value, ok := d.OptString("duration"); ok {
+		var err error
+		if duration, err = parsePause(value); err != nil {
+			return err
+		}
+	}
+
+	guild := models.Guild{
+		ID:          *guildID,
+		PausedUntil: time.Now().Add(duration).UTC(),
+		PausedBy:    event.User().ID,
+	}
+
+	logger.DebugContext(event.Ctx, "pausing guild", "paused_until", guild.PausedUntil)
+
+	if _, err := p.db.NewUpdate().Model(&guild).Column("paused_until", "paused_by").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to pause guild: %w", err)
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(fmt.Sprintf("Disruptions paused until <t:%d:F> <t:%d:R>, use `/resume` to resume earlier", guild.PausedUntil.Unix(), guild.PausedUntil.Unix()))
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+// parsePause parses a Go duration or a number of days like 3d.
+func parsePause(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse duration: %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("failed to parse duration: %w", err)
+		}
+	}
+
+	if d < time.Minute || d > maxPause {
+		return 0, fmt.Errorf("invalid duration: %s, must be between 1m and 30d", s)
+	}
+
+	return d, nil
+}
+
+var _ disruptor.Command = (*pause)(nil)
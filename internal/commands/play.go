@@ -1,19 +1,29 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
+	"github.com/uptrace/bun"
 
 	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
 	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/internal/voice"
 	"github.com/XanderD99/disruptor/pkg/logging"
 )
 
-type play struct{}
+type play struct {
+	db    *bun.DB
+	voice *voice.Limiter
+}
 
-func Play() disruptor.Command { return play{} }
+func Play(db *bun.DB, limiter *voice.Limiter) disruptor.Command {
+	return play{db: db, voice: limiter}
+}
 
 // Load implements disruptor.Command.
 func (p play) Load(r handler.Router) {
@@ -65,15 +75,43 @@ func (p play) handle(_ discord.SlashCommandInteractionData, event *handler.Comma
 		return fmt.Errorf("failed to get random sound: %w", err)
 	}
 
+	_, unlock, ok := p.voice.LockGuild(event.Ctx, *event.GuildID())
+	if !ok {
+		return fmt.Errorf("I am playing a sound in this server already, try again in a moment")
+	}
+
+	// commands do not wait for the voice budget, the interaction would expire
+	release, err := p.voice.TryAcquire()
+	if errors.Is(err, voice.ErrSaturated) {
+		unlock()
+		return fmt.Errorf("I am playing in too many voice channels right now, try again in a moment")
+	}
+	if err != nil {
+		unlock()
+		return fmt.Errorf("failed to reserve voice connection: %w", err)
+	}
+	release = sync.OnceFunc(func() {
+		release()
+		unlock()
+	})
+
 	content := fmt.Sprintf("Playing %s in <#%s>", sound.Name, voiceState.ChannelID.String())
 	response := discord.NewMessageUpdateBuilder().SetContent(content).Build()
 
 	if _, err := event.UpdateInteractionResponse(response); err != nil {
+		release()
 		return fmt.Errorf("failed to update interaction response: %w", err)
 	}
 	go func() { // fire and forget. If we don't do that here the sound could play longer than the max amount of time that discord allows between interaction and response
+		defer release()
 		if err := util.PlaySound(event.Ctx, client, *event.GuildID(), *voiceState.ChannelID, sound.URL()); err != nil {
 			logger.ErrorContext(event.Ctx, "failed to play sound", "error", err)
+			return
+		}
+
+		// the bot can play again, resume disruptions held back after failures
+		if err := handlers.CloseCircuit(event.Ctx, p.db, *event.GuildID()); err != nil {
+			logger.ErrorContext(event.Ctx, "failed to close circuit", "error", err)
 		}
 	}()
 
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/pkg/logging"
+)
+
+type quiet struct {
+	manager *scheduler.Manager
+	db      *bun.DB
+}
+
+func Quiet(db *bun.DB, manager *scheduler.Manager) disruptor.Command {
+	return quiet{manager: manager, db: db}
+}
+
+// Load implements disruptor.Command.
+func (q quiet) Load(r handler.Router) {
+	r.Route("/quiet", func(r handler.Router) {
+		r.SlashCommand("/add", q.add)
+		r.SlashCommand("/list", q.list)
+		r.SlashCommand("/remove", q.remove)
+	})
+}
+
+// Options implements disruptor.Command.
+func (q quiet) Options() discord.SlashCommandCreate {
+	weekdays := make([]discord.ApplicationCommandOptionChoiceInt, 0, 7)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays = append(weekdays, discord.ApplicationCommandOptionChoiceInt{Name: day.String(), Value: int(day)})
+	}
+
+	return discord.SlashCommandCreate{
+		Name:                     "quiet",
+		Description:              "Manage quiet hours in which the bot will not disrupt",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "add",
+				Description: "Add quiet hours, windows that end before they start wrap past midnight",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:        "start",
+						Description: "Local start time, example: 23:00",
+						Required:    true,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "end",
+						Description: "Local end time, example: 07:30",
+						Required:    true,
+					},
+					discord.ApplicationCommandOptionInt{
+						Name:        "day",
+						Description: "Only on this day of the week (default every day)",
+						Choices:     weekdays,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "timezone",
+						Description: "IANA timezone of this server. example: Europe/Brussels (default UTC)",
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "list",
+				Description: "List the quiet hours of this server",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "remove",
+				Description: "Remove quiet hours",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionInt{
+						Name:        "id",
+						Description: "ID of the quiet hours, see /quiet list",
+						Required:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (q quiet) add(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	// Get logger from context (added by the middleware)
+	logger := logging.FromContext(event.Ctx)
+
+	guild, err := q.guild(event)
+	if err != nil {
+		return err
+	}
+
+	start, err := parseClock(d.String("start"))
+	if err != nil {
+		return err
+	}
+
+	end, err := parseClock(d.String("end"))
+	if err != nil {
+		return err
+	}
+
+	window := models.QuietHours{GuildID: guild.ID, Start: start, End: end}
+	if day, ok := d.OptInt("day"); ok {
+		weekday := time.Weekday(day)
+		window.Weekday = &weekday
+	}
+
+	if timezone, ok := d.OptString("timezone"); ok {
+		guild.Timezone = timezone
+		if _, err := guild.Location(); err != nil {
+			return err
+		}
+
+		if _, err := q.db.NewUpdate().Model(&guild).Column("timezone").WherePK().Exec(event.Ctx); err != nil {
+			return fmt.Errorf("failed to update guild timezone: %w", err)
+		}
+
+		// cron schedules are evaluated in the guild timezone as well
+		if err := handlers.Schedule(event.Ctx, q.manager, guild); err != nil {
+			return fmt.Errorf("failed to add guild to voice audio scheduler manager: %w", err)
+		}
+	}
+
+	logger.DebugContext(event.Ctx, "adding quiet hours", "quiet_hours", window.String(), "timezone", guild.Timezone)
+
+	if _, err := q.db.NewInsert().Model(&window).Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to add quiet hours: %w", err)
+	}
+
+	return q.respond(event, fmt.Sprintf("Added quiet hours `#%d`: %s (%s)", window.ID, window, timezoneName(guild)))
+}
+
+func (q quiet) list(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := q.guild(event)
+	if err != nil {
+		return err
+	}
+
+	if len(guild.QuietHours) == 0 {
+		return q.respond(event, "There are no quiet hours for this server.")
+	}
+
+	lines := make([]string, 0, len(guild.QuietHours))
+	for _, window := range guild.QuietHours {
+		lines = append(lines, fmt.Sprintf("`#%d` %s", window.ID, window))
+	}
+
+	return q.respond(event, fmt.Sprintf("Quiet hours (%s):\n%s", timezoneName(guild), strings.Join(lines, "\n")))
+}
+
+func (q quiet) remove(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	id := d.Int("id")
+	res, err := q.db.NewDelete().Model((*models.QuietHours)(nil)).Where("id = ? AND guild_id = ?", id, *guildID).Exec(event.Ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove quiet hours: %w", err)
+	}
+
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("quiet hours `#%d` not found", id)
+	}
+
+	return q.respond(event, fmt.Sprintf("Removed quiet hours `#%d`", id))
+}
+
+func (q quiet) guild(event *handler.CommandEvent) (models.Guild, error) {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return models.Guild{}, fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := q.db.NewSelect().Model(&guild).WherePK().Relation("QuietHours").Scan(event.Ctx); err != nil {
+		return models.Guild{}, fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	return guild, nil
+}
+
+func (q quiet) respond(event *handler.CommandEvent, description string) error {
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+// parseClock parses a HH:MM time of day into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func timezoneName(guild models.Guild) string {
+	if guild.Timezone == "" {
+		return "UTC"
+	}
+	return guild.Timezone
+}
+
+var _ disruptor.Command = (*quiet)(nil)
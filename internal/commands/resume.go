@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+type resume struct {
+	db *bun.DB
+}
+
+func Resume(db *bun.DB) disruptor.Command {
+	return resume{db: db}
+}
+
+// Load implements disruptor.Command.
+func (r resume) Load(router handler.Router) {
+	router.SlashCommand("/resume", r.handle)
+}
+
+// Options implements disruptor.Command.
+func (r resume) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "resume",
+		Description:              "Resume paused disruptions",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+	}
+}
+
+func (r resume) handle(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := r.db.NewSelect().Model(&guild).WherePK().Scan(event.Ctx); err != nil {
+		return fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+
+	if !guild.Paused(time.Now()) {
+		embed.SetDescription("Disruptions are not paused")
+	} else {
+		guild.PausedUntil, guild.PausedBy = time.Time{}, 0
+		if _, err := r.db.NewUpdate().Model(&guild).Column("paused_until", "paused_by").WherePK().Exec(event.Ctx); err != nil {
+			return fmt.Errorf("failed to resume guild: %w", err)
+		}
+		embed.SetDescription("Disruptions resumed")
+	}
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+var _ disruptor.Command = (*resume)(nil)
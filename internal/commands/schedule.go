@@ -0,0 +1,508 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/pkg/logging"
+)
+
+const (
+	previewCount = 5
+	// maxPendingJobs keeps the job list of a guild within a single embed.
+	maxPendingJobs = 25
+)
+
+type schedule struct {
+	manager    *scheduler.Manager
+	dispatcher *scheduler.Dispatcher
+	db         *bun.DB
+}
+
+func Schedule(db *bun.DB, manager *scheduler.Manager, dispatcher *scheduler.Dispatcher) disruptor.Command {
+	return schedule{manager: manager, dispatcher: dispatcher, db: db}
+}
+
+// Load implements disruptor.Command.
+func (s schedule) Load(r handler.Router) {
+	r.Route("/schedule", func(r handler.Router) {
+		r.SlashCommand("/set", s.set)
+		r.SlashCommand("/preview", s.preview)
+		r.SlashCommand("/clear", s.clear)
+		r.SlashCommand("/window", s.window)
+		r.SlashCommand("/at", s.at)
+		r.SlashCommand("/jobs", s.jobs)
+		r.SlashCommand("/cancel", s.cancel)
+		r.Autocomplete("/at", s.autocompleteAt)
+		r.Autocomplete("/cancel", s.autocompleteCancel)
+	})
+}
+
+// Options implements disruptor.Command.
+func (s schedule) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "schedule",
+		Description:              "Use a cron schedule for disruptions or queue one for a specific time",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "set",
+				Description: "Set the cron schedule of this server",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:        "expression",
+						Description: "Cron expression: minute hour day month weekday. example: \"*/30 18-22 * * mon-fri\"",
+						Required:    true,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "timezone",
+						Description: "IANA timezone the expression is evaluated in. example: Europe/Brussels (default UTC)",
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "preview",
+				Description: "Preview the next disruption times",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "clear",
+				Description: "Remove the cron schedule and go back to the interval",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "window",
+				Description: "Only disrupt on the interval inside active hours, leave the expression empty to remove them",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:        "expression",
+						Description: "Cron expression of the active minutes. example: \"* 18-22 * * mon-fri\" for weekdays 18:00-23:00",
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "timezone",
+						Description: "IANA timezone the expression is evaluated in. example: Europe/Brussels (default UTC)",
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "at",
+				Description: "Queue a disruption that runs once at a specific time",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:        "time",
+						Description: "When to disrupt, HH:MM or YYYY-MM-DD HH:MM. example: 21:30",
+						Required:    true,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:         "channel",
+						Description:  "Voice channel to join",
+						Required:     true,
+						Autocomplete: true,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:         "sound",
+						Description:  "Soundboard sound to play",
+						Required:     true,
+						Autocomplete: true,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "timezone",
+						Description: "IANA timezone of the time. example: Europe/Brussels (default the timezone of the schedule)",
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "jobs",
+				Description: "List the queued disruptions",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "cancel",
+				Description: "Cancel a queued disruption",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionInt{
+						Name:         "job",
+						Description:  "Number of the queued disruption",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s schedule) set(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	// Get logger from context (added by the middleware)
+	logger := logging.FromContext(event.Ctx)
+
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	guild.Cron = strings.TrimSpace(d.String("expression"))
+	if timezone, ok := d.OptString("timezone"); ok {
+		guild.Timezone = timezone
+	}
+
+	timing, err := handlers.Timing(guild, s.manager.Now())
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	logger.DebugContext(event.Ctx, "updating guild schedule", "cron", guild.Cron, "timezone", guild.Timezone)
+
+	if _, err := s.db.NewUpdate().Model(&guild).Column("cron", "timezone").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update guild schedule: %w", err)
+	}
+
+	if err := handlers.Schedule(event.Ctx, s.manager, guild); err != nil {
+		return fmt.Errorf("failed to add guild to voice audio scheduler manager: %w", err)
+	}
+
+	return s.respond(event, fmt.Sprintf("Schedule set to: `%s`", timing), guild, s.upcoming(guild, timing))
+}
+
+func (s schedule) preview(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	timing, err := handlers.Timing(guild, s.manager.Now())
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	return s.respond(event, fmt.Sprintf("Current schedule: `%s`", timing), guild, s.upcoming(guild, timing))
+}
+
+func (s schedule) clear(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	guild.Cron = ""
+
+	if _, err := s.db.NewUpdate().Model(&guild).Column("cron").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update guild schedule: %w", err)
+	}
+
+	if err := handlers.Schedule(event.Ctx, s.manager, guild); err != nil {
+		return fmt.Errorf("failed to add guild to voice audio scheduler manager: %w", err)
+	}
+
+	description := fmt.Sprintf("Schedule removed, using interval: %s", formatInterval(guild))
+	if guild.ActiveHours != "" {
+		description += fmt.Sprintf(" within active hours `%s`", guild.ActiveHours)
+	}
+
+	return s.respond(event, description, guild, nil)
+}
+
+func (s schedule) window(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	guild.ActiveHours = strings.TrimSpace(d.String("expression"))
+	if timezone, ok := d.OptString("timezone"); ok {
+		guild.Timezone = timezone
+	}
+
+	timing, err := handlers.Timing(guild, s.manager.Now())
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	if _, err := s.db.NewUpdate().Model(&guild).Column("active_hours", "timezone").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update guild schedule: %w", err)
+	}
+
+	if err := handlers.Schedule(event.Ctx, s.manager, guild); err != nil {
+		return fmt.Errorf("failed to add guild to voice audio scheduler manager: %w", err)
+	}
+
+	description := fmt.Sprintf("Active hours removed, schedule: `%s`", timing)
+	switch {
+	case guild.ActiveHours != "" && guild.Cron != "":
+		description = fmt.Sprintf("Active hours set, they apply once the cron schedule is removed with `/schedule clear`. Schedule: `%s`", timing)
+	case guild.ActiveHours != "":
+		description = fmt.Sprintf("Schedule set to: `%s`", timing)
+	}
+
+	return s.respond(event, description, guild, s.upcoming(guild, timing))
+}
+
+func (s schedule) at(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	if timezone, ok := d.OptString("timezone"); ok {
+		guild.Timezone = timezone
+	}
+
+	loc, err := guild.Location()
+	if err != nil {
+		return err
+	}
+
+	runAt, err := parseRunAt(d.String("time"), time.Now().In(loc))
+	if err != nil {
+		return err
+	}
+
+	channelID, err := snowflake.Parse(d.String("channel"))
+	if err != nil {
+		return fmt.Errorf("pick a voice channel from the list")
+	}
+	if channel, ok := event.Client().Caches.GuildVoiceChannel(channelID); !ok || channel.GuildID() != guild.ID {
+		return fmt.Errorf("pick a voice channel from the list")
+	}
+
+	soundID, err := snowflake.Parse(d.String("sound"))
+	if err != nil {
+		return fmt.Errorf("pick a sound from the list")
+	}
+	sound, ok := event.Client().Caches.GuildSoundboardSound(guild.ID, soundID)
+	if !ok {
+		return fmt.Errorf("pick a sound from the list")
+	}
+
+	pending, err := s.dispatcher.Pending(event.Ctx, guild.ID)
+	if err != nil {
+		return err
+	}
+	if len(pending) >= maxPendingJobs {
+		return fmt.Errorf("there are already %d disruptions queued, cancel one first", len(pending))
+	}
+
+	job := models.Job{
+		GuildID:   guild.ID,
+		ChannelID: channelID,
+		SoundID:   soundID,
+		RunAt:     runAt,
+		CreatedBy: event.User().ID,
+	}
+	if err := s.dispatcher.Schedule(event.Ctx, &job); err != nil {
+		return err
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(fmt.Sprintf("Queued disruption `#%d`: **%s** in <#%s> <t:%d:F> <t:%d:R>", job.ID, sound.Name, channelID, runAt.Unix(), runAt.Unix()))
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+func (s schedule) jobs(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	pending, err := s.dispatcher.Pending(event.Ctx, *guildID)
+	if err != nil {
+		return err
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+
+	if len(pending) == 0 {
+		embed.SetDescription("No disruptions queued, use `/schedule at` to queue one")
+	} else {
+		lines := make([]string, 0, len(pending))
+		for _, job := range pending {
+			line := fmt.Sprintf("`#%d` **%s** in <#%s> <t:%d:F> <t:%d:R> by <@%s>", job.ID, jobSound(event.Client(), job), job.ChannelID, job.RunAt.Unix(), job.RunAt.Unix(), job.CreatedBy)
+			if job.Missed() {
+				line += " ⚠️ missed while the bot was offline, cancel it with `/schedule cancel`"
+			}
+			lines = append(lines, line)
+		}
+		embed.SetDescription(strings.Join(lines, "\n"))
+	}
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+func (s schedule) cancel(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	id := d.Int("job")
+	cancelled, err := s.dispatcher.Cancel(event.Ctx, *guildID, int64(id))
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return fmt.Errorf("there is no queued disruption `#%d`", id)
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(fmt.Sprintf("Cancelled disruption `#%d`", id))
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+func (s schedule) autocompleteAt(event *handler.AutocompleteEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return event.AutocompleteResult(nil)
+	}
+
+	focused := event.Data.Focused()
+	query := event.Data.String(focused.Name)
+
+	switch focused.Name {
+	case "channel":
+		return event.AutocompleteResult(voiceChannelChoices(event.Client(), *guildID, query))
+	case "sound":
+		return event.AutocompleteResult(soundChoices(event.Client(), *guildID, query))
+	default:
+		return event.AutocompleteResult(nil)
+	}
+}
+
+func (s schedule) autocompleteCancel(event *handler.AutocompleteEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return event.AutocompleteResult(nil)
+	}
+
+	pending, err := s.dispatcher.Pending(event.Ctx, *guildID)
+	if err != nil {
+		return err
+	}
+
+	choices := make([]discord.AutocompleteChoice, 0, min(len(pending), maxChoices))
+	for _, job := range pending[:min(len(pending), maxChoices)] {
+		name := fmt.Sprintf("#%d %s at %s UTC", job.ID, jobSound(event.Client(), job), job.RunAt.UTC().Format("2006-01-02 15:04"))
+		if job.Missed() {
+			name += " (missed)"
+		}
+		choices = append(choices, discord.AutocompleteChoiceInt{Name: name, Value: int(job.ID)})
+	}
+
+	return event.AutocompleteResult(choices)
+}
+
+// jobSound returns the name of the sound of a job, sounds can be removed after the job was queued.
+func jobSound(client *bot.Client, job models.Job) string {
+	if sound, ok := client.Caches.GuildSoundboardSound(job.GuildID, job.SoundID); ok {
+		return sound.Name
+	}
+	return "deleted sound"
+}
+
+func (s schedule) guild(event *handler.CommandEvent) (models.Guild, error) {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return models.Guild{}, fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := s.db.NewSelect().Model(&guild).WherePK().Relation("QuietHours").Scan(event.Ctx); err != nil {
+		return models.Guild{}, fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	return guild, nil
+}
+
+// upcoming returns the next fire times of a guild, starting at the time it is scheduled for.
+func (s schedule) upcoming(guild models.Guild, timing scheduler.Timing) []time.Time {
+	first, ok := s.manager.NextFire(handlers.HandlerTypeRandomVoiceJoin, guild.ID)
+	if !ok {
+		first = timing.Next(s.manager.Now())
+	}
+
+	return scheduler.Preview(timing, first, previewCount)
+}
+
+// respond shows the upcoming fire times, striking through the ones that fall in quiet hours or a pause.
+func (s schedule) respond(event *handler.CommandEvent, description string, guild models.Guild, upcoming []time.Time) error {
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+
+	if len(upcoming) > 0 {
+		lines := make([]string, 0, len(upcoming))
+		for _, t := range upcoming {
+			line := fmt.Sprintf("<t:%d:F> <t:%d:R>", t.Unix(), t.Unix())
+			switch {
+			case guild.Quiet(t):
+				line = fmt.Sprintf("~~%s~~ quiet hours", line)
+			case guild.Paused(t):
+				line = fmt.Sprintf("~~%s~~ paused", line)
+			}
+			lines = append(lines, line)
+		}
+		embed.AddField("Upcoming", strings.Join(lines, "\n"), false)
+	}
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+// parseRunAt parses HH:MM as the next time the clock shows it and YYYY-MM-DD
+// HH:MM as that exact time, both in the location of now.
+func parseRunAt(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, now.Location()); err == nil {
+		if !t.After(now) {
+			return time.Time{}, fmt.Errorf("%s is in the past", value)
+		}
+		return t, nil
+	}
+
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use HH:MM or YYYY-MM-DD HH:MM", value)
+	}
+
+	t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+var _ disruptor.Command = (*schedule)(nil)
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// maxErrorLength keeps the last error of a scheduler within the 1024 characters of an embed field.
+const maxErrorLength = 500
+
+type schedulers struct {
+	manager *scheduler.Manager
+	owners  *owners
+}
+
+// Schedulers shows the state of every scheduler, it can only be used by the
+// bot owners.
+func Schedulers(manager *scheduler.Manager, ownerIDs []snowflake.ID) disruptor.Command {
+	return schedulers{manager: manager, owners: newOwners(ownerIDs)}
+}
+
+// Load implements disruptor.Command.
+func (s schedulers) Load(r handler.Router) {
+	r.SlashCommand("/schedulers", s.handle)
+}
+
+// Options implements disruptor.Command.
+func (s schedulers) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:        "schedulers",
+		Description: "Show the state of the schedulers (bot owners only)",
+	}
+}
+
+func (s schedulers) handle(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	if err := s.owners.check(event); err != nil {
+		return err
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetTitle("Schedulers")
+
+	snapshots := s.manager.Snapshot()
+	if len(snapshots) == 0 {
+		embed.SetDescription("There are no schedulers.")
+	}
+
+	for _, snapshot := range snapshots {
+		if len(embed.Fields) == 25 { // embed field limit
+			embed.SetFooterText(fmt.Sprintf("%d more not shown", len(snapshots)-25))
+			break
+		}
+		embed.AddField(schedulerName(snapshot), describeSnapshot(snapshot), false)
+	}
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+// schedulerName names a scheduler by its key and interval, cron schedules are
+// grouped by the mean gap between their fires.
+func schedulerName(snapshot scheduler.Snapshot) string {
+	return fmt.Sprintf("%s (%s)", snapshot.Key, snapshot.Interval)
+}
+
+func describeSnapshot(snapshot scheduler.Snapshot) string {
+	lines := []string{
+		fmt.Sprintf("Guilds: %d, running: %t, handlers in flight: %d", snapshot.Guilds, snapshot.Running, snapshot.InFlight),
+		fmt.Sprintf("Runs: %d, failures: %d, timed out: %d, skipped: %d", snapshot.Runs, snapshot.Failures, snapshot.TimedOut, snapshot.Skipped),
+	}
+
+	if !snapshot.NextFire.IsZero() {
+		lines = append(lines, fmt.Sprintf("Next fire: <t:%d:R>", snapshot.NextFire.Unix()))
+	}
+
+	if snapshot.LastRun.IsZero() {
+		lines = append(lines, "Last run: never")
+	} else {
+		lines = append(lines, fmt.Sprintf("Last run: <t:%d:R> in %s", snapshot.LastRun.Unix(), snapshot.LastDuration))
+	}
+
+	if snapshot.LastError != "" {
+		lines = append(lines, fmt.Sprintf("Last error: `%s`", truncate(snapshot.LastError, maxErrorLength)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// truncate shortens s to at most n characters, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+var _ disruptor.Command = (*schedulers)(nil)
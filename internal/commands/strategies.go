@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+type strategies struct {
+	db *bun.DB
+}
+
+func Strategies(db *bun.DB) disruptor.Command {
+	return strategies{db: db}
+}
+
+// Load implements disruptor.Command.
+func (s strategies) Load(r handler.Router) {
+	r.Route("/strategies", func(r handler.Router) {
+		r.SlashCommand("/list", s.list)
+		r.SlashCommand("/enable", s.enable)
+		r.SlashCommand("/disable", s.disable)
+		r.Autocomplete("/enable", s.autocomplete)
+		r.Autocomplete("/disable", s.autocomplete)
+	})
+}
+
+var (
+	minStrategyWeight = 1
+	maxStrategyWeight = 100
+)
+
+// Options implements disruptor.Command.
+func (s strategies) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "strategies",
+		Description:              "Choose how the server is disrupted",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "list",
+				Description: "Show the enabled strategies and their chance of being picked",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "enable",
+				Description: "Enable a strategy or change its weight",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:         "strategy",
+						Description:  "The strategy to enable",
+						Required:     true,
+						Autocomplete: true,
+					},
+					discord.ApplicationCommandOptionInt{
+						Name:        "weight",
+						Description: "Chance of being picked relative to the other strategies (default 1)",
+						MinValue:    &minStrategyWeight,
+						MaxValue:    &maxStrategyWeight,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "disable",
+				Description: "Disable a strategy",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:         "strategy",
+						Description:  "The strategy to disable",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s strategies) list(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	return s.respond(event, "", guild)
+}
+
+func (s strategies) enable(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	name := d.String("strategy")
+	if _, ok := handlers.GetStrategy(name); !ok {
+		return fmt.Errorf("unknown strategy %q", name)
+	}
+
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	weight := d.Int("weight")
+	if weight == 0 {
+		weight = 1
+	}
+
+	// store the default strategy along with the first strategy a guild enables, so it stays enabled
+	enabled := slices.Clone(handlers.GuildStrategies(guild))
+	i := slices.IndexFunc(enabled, func(strategy models.Strategy) bool { return strategy.Name == name })
+	if i < 0 {
+		enabled = append(enabled, models.Strategy{GuildID: guild.ID, Name: name})
+		i = len(enabled) - 1
+	}
+	enabled[i].Weight = weight
+
+	if _, err := s.db.NewInsert().Model(&enabled).On("CONFLICT (guild_id, name) DO UPDATE").Set("weight = EXCLUDED.weight").Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to enable strategy %s: %w", name, err)
+	}
+	guild.Strategies = enabled
+
+	return s.respond(event, fmt.Sprintf("Enabled `%s` with weight %d", name, weight), guild)
+}
+
+func (s strategies) disable(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	name := d.String("strategy")
+
+	guild, err := s.guild(event)
+	if err != nil {
+		return err
+	}
+
+	enabled := handlers.GuildStrategies(guild)
+	i := slices.IndexFunc(enabled, func(strategy models.Strategy) bool { return strategy.Name == name })
+	if i < 0 {
+		return fmt.Errorf("strategy %s is not enabled", name)
+	}
+	if len(enabled) == 1 {
+		return fmt.Errorf("at least one strategy must stay enabled, use /pause to stop disruptions")
+	}
+
+	if _, err := s.db.NewDelete().Model((*models.Strategy)(nil)).Where("guild_id = ? AND name = ?", guild.ID, name).Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to disable strategy %s: %w", name, err)
+	}
+	guild.Strategies = slices.Delete(slices.Clone(enabled), i, i+1)
+
+	return s.respond(event, fmt.Sprintf("Disabled `%s`", name), guild)
+}
+
+func (s strategies) autocomplete(event *handler.AutocompleteEvent) error {
+	query := strings.ToLower(event.Data.String("strategy"))
+
+	choices := make([]discord.AutocompleteChoice, 0)
+	for _, strategy := range handlers.Strategies() {
+		if len(choices) == maxChoices {
+			break
+		}
+		if strings.Contains(strategy.Name(), query) {
+			choices = append(choices, discord.AutocompleteChoiceString{Name: strategy.Name(), Value: strategy.Name()})
+		}
+	}
+
+	return event.AutocompleteResult(choices)
+}
+
+func (s strategies) guild(event *handler.CommandEvent) (models.Guild, error) {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return models.Guild{}, fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := s.db.NewSelect().Model(&guild).WherePK().Relation("Strategies").Scan(event.Ctx); err != nil {
+		return models.Guild{}, fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	return guild, nil
+}
+
+// respond lists the enabled strategies of the guild with their chance of
+// being picked and the strategies that can still be enabled below description.
+func (s strategies) respond(event *handler.CommandEvent, description string, guild models.Guild) error {
+	enabled := handlers.GuildStrategies(guild)
+
+	total := 0
+	for _, strategy := range enabled {
+		total += strategy.Weight
+	}
+
+	var b strings.Builder
+	for _, strategy := range enabled {
+		fmt.Fprintf(&b, "`%s` weight %d (%.0f%%)\n", strategy.Name, strategy.Weight, float64(strategy.Weight)/float64(max(total, 1))*100)
+	}
+
+	var available strings.Builder
+	for _, strategy := range handlers.Strategies() {
+		if !slices.ContainsFunc(enabled, func(s models.Strategy) bool { return s.Name == strategy.Name() }) {
+			fmt.Fprintf(&available, "`%s` %s\n", strategy.Name(), strategy.Description())
+		}
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+	embed.AddField("Enabled", b.String(), false)
+	if available.Len() > 0 {
+		embed.AddField("Available", available.String(), false)
+	}
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+var _ disruptor.Command = (*strategies)(nil)
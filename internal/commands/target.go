@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+type target struct {
+	db *bun.DB
+}
+
+func Target(db *bun.DB) disruptor.Command {
+	return target{db: db}
+}
+
+// Load implements disruptor.Command.
+func (t target) Load(r handler.Router) {
+	r.Route("/target", func(r handler.Router) {
+		r.SlashCommand("/set", t.set)
+		r.SlashCommand("/clear", t.clear)
+		r.SlashCommand("/show", t.show)
+	})
+}
+
+// Options implements disruptor.Command.
+func (t target) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "target",
+		Description:              "Choose the member followed by the follow_target strategy",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "set",
+				Description: "Follow a member, they must have agreed with /consent give",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionUser{
+						Name:        "user",
+						Description: "The member to follow",
+						Required:    true,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "clear",
+				Description: "Stop following a member",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "show",
+				Description: "Show the member that is followed",
+			},
+		},
+	}
+}
+
+func (t target) set(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := t.guild(event)
+	if err != nil {
+		return err
+	}
+
+	user := d.User("user")
+	if user.Bot {
+		return fmt.Errorf("bots can not be targeted")
+	}
+
+	targetable, err := handlers.Targetable(event.Ctx, t.db, guild.ID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !targetable {
+		return fmt.Errorf("<@%s> did not agree to be targeted, they can opt in with `/consent give`", user.ID)
+	}
+
+	guild.TargetID = user.ID
+	if _, err := t.db.NewUpdate().Model(&guild).Column("target_id").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to update target: %w", err)
+	}
+
+	return t.respond(event, guild)
+}
+
+func (t target) clear(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := t.guild(event)
+	if err != nil {
+		return err
+	}
+
+	guild.TargetID = 0
+	if _, err := t.db.NewUpdate().Model(&guild).Column("target_id").WherePK().Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to clear target: %w", err)
+	}
+
+	return t.respond(event, guild)
+}
+
+func (t target) show(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guild, err := t.guild(event)
+	if err != nil {
+		return err
+	}
+
+	return t.respond(event, guild)
+}
+
+func (t target) guild(event *handler.CommandEvent) (models.Guild, error) {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return models.Guild{}, fmt.Errorf("this command can only be used in a guild")
+	}
+
+	guild := models.Guild{ID: *guildID}
+	if err := t.db.NewSelect().Model(&guild).WherePK().Relation("Strategies").Scan(event.Ctx); err != nil {
+		return models.Guild{}, fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	return guild, nil
+}
+
+// respond shows the target of the guild and whether the strategy following it is enabled.
+func (t target) respond(event *handler.CommandEvent, guild models.Guild) error {
+	description := "Nobody is targeted"
+	if guild.TargetID != 0 {
+		description = fmt.Sprintf("Targeting <@%s>", guild.TargetID)
+		if state, ok := event.Client().Caches.VoiceState(guild.ID, guild.TargetID); ok && state.ChannelID != nil {
+			description += fmt.Sprintf(", currently in <#%s>", *state.ChannelID)
+		}
+	}
+
+	enabled := false
+	for _, strategy := range handlers.GuildStrategies(guild) {
+		enabled = enabled || strategy.Name == handlers.StrategyFollowTarget
+	}
+	if !enabled {
+		description += fmt.Sprintf("\nEnable it with `/strategies enable strategy:%s`", handlers.StrategyFollowTarget)
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+var _ disruptor.Command = (*target)(nil)
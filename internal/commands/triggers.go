@@ -0,0 +1,281 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+const (
+	// maxTriggers is the most voice triggers a guild can have
+	maxTriggers = 10
+
+	defaultTriggerChance   = 50
+	defaultTriggerCooldown = 30 * time.Minute
+)
+
+var (
+	minTriggerMembers = 2
+	maxTriggerMembers = 99
+	minTriggerChance  = 1
+	maxTriggerChance  = 100
+)
+
+type triggers struct {
+	db *bun.DB
+}
+
+func Triggers(db *bun.DB) disruptor.Command {
+	return triggers{db: db}
+}
+
+// Load implements disruptor.Command.
+func (t triggers) Load(r handler.Router) {
+	r.Route("/triggers", func(r handler.Router) {
+		r.SlashCommand("/crowd", t.crowd)
+		r.SlashCommand("/user", t.user)
+		r.SlashCommand("/afk", t.afk)
+		r.SlashCommand("/list", t.list)
+		r.SlashCommand("/remove", t.remove)
+		r.Autocomplete("/remove", t.autocompleteRemove)
+	})
+}
+
+// triggerOptions are the options every kind of trigger has.
+func triggerOptions(options ...discord.ApplicationCommandOption) []discord.ApplicationCommandOption {
+	return append(options,
+		discord.ApplicationCommandOptionInt{
+			Name:        "chance",
+			Description: "Chance of a disruption once the trigger matches (1-100, default 50)",
+			MinValue:    &minTriggerChance,
+			MaxValue:    &maxTriggerChance,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "cooldown",
+			Description: "How long the trigger waits before it fires again. example: 30m, 2h (default 30m)",
+		},
+	)
+}
+
+// Options implements disruptor.Command.
+func (t triggers) Options() discord.SlashCommandCreate {
+	return discord.SlashCommandCreate{
+		Name:                     "triggers",
+		Description:              "Disrupt on voice activity instead of waiting for the schedule",
+		DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageGuild),
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "crowd",
+				Description: "Disrupt a voice channel once it reaches a number of members",
+				Options: triggerOptions(discord.ApplicationCommandOptionInt{
+					Name:        "members",
+					Description: "Members the channel must reach",
+					Required:    true,
+					MinValue:    &minTriggerMembers,
+					MaxValue:    &maxTriggerMembers,
+				}),
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "user",
+				Description: "Disrupt when a member joins a voice channel, they must have agreed with /consent give",
+				Options: triggerOptions(discord.ApplicationCommandOptionUser{
+					Name:        "user",
+					Description: "The member to wait for",
+					Required:    true,
+				}),
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "afk",
+				Description: "Disrupt when someone returns from the AFK channel",
+				Options:     triggerOptions(),
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "list",
+				Description: "List the voice triggers",
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "remove",
+				Description: "Remove a voice trigger",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionInt{
+						Name:         "trigger",
+						Description:  "The trigger to remove",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (t triggers) crowd(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	return t.add(d, event, models.Trigger{Kind: models.TriggerCrowd, Members: d.Int("members")})
+}
+
+func (t triggers) user(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	user := d.User("user")
+	if user.Bot {
+		return fmt.Errorf("bots can not be targeted")
+	}
+
+	targetable, err := handlers.Targetable(event.Ctx, t.db, *guildID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !targetable {
+		return fmt.Errorf("<@%s> did not agree to be targeted, they can opt in with `/consent give`", user.ID)
+	}
+
+	return t.add(d, event, models.Trigger{Kind: models.TriggerUserJoin, UserID: user.ID})
+}
+
+func (t triggers) afk(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	return t.add(d, event, models.Trigger{Kind: models.TriggerAFKReturn})
+}
+
+// add stores trigger with the chance and cooldown options of the command.
+func (t triggers) add(d discord.SlashCommandInteractionData, event *handler.CommandEvent, trigger models.Trigger) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	count, err := t.db.NewSelect().Model((*models.Trigger)(nil)).Where("guild_id = ?", *guildID).Count(event.Ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count triggers: %w", err)
+	}
+	if count >= maxTriggers {
+		return fmt.Errorf("a server can have at most %d triggers, remove one with `/triggers remove`", maxTriggers)
+	}
+
+	trigger.GuildID = *guildID
+	trigger.Chance = models.Chance(defaultTriggerChance)
+	if chance, ok := d.OptInt("chance"); ok {
+		trigger.Chance = models.Chance(chance)
+	}
+
+	trigger.Cooldown = defaultTriggerCooldown
+	if value, ok := d.OptString("cooldown"); ok {
+		if trigger.Cooldown, err = parseIntervalDuration(value); err != nil {
+			return err
+		}
+	}
+
+	if _, err := t.db.NewInsert().Model(&trigger).Exec(event.Ctx); err != nil {
+		return fmt.Errorf("failed to add trigger: %w", err)
+	}
+
+	return t.respond(event, fmt.Sprintf("Added trigger `#%d`\n%s", trigger.ID, trigger))
+}
+
+func (t triggers) list(_ discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	all := make([]models.Trigger, 0)
+	if err := t.db.NewSelect().Model(&all).Where("guild_id = ?", *guildID).Order("id ASC").Scan(event.Ctx); err != nil {
+		return fmt.Errorf("failed to list triggers: %w", err)
+	}
+
+	if len(all) == 0 {
+		return t.respond(event, "No voice triggers, add one with `/triggers crowd`, `/triggers user` or `/triggers afk`")
+	}
+
+	lines := make([]string, 0, len(all))
+	for _, trigger := range all {
+		lines = append(lines, fmt.Sprintf("`#%d` %s", trigger.ID, trigger))
+	}
+
+	return t.respond(event, strings.Join(lines, "\n"))
+}
+
+func (t triggers) remove(d discord.SlashCommandInteractionData, event *handler.CommandEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return fmt.Errorf("this command can only be used in a guild")
+	}
+
+	id := d.Int("trigger")
+	res, err := t.db.NewDelete().Model((*models.Trigger)(nil)).Where("id = ? AND guild_id = ?", id, *guildID).Exec(event.Ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove trigger %d: %w", id, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to remove trigger %d: %w", id, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("there is no trigger `#%d`", id)
+	}
+
+	return t.respond(event, fmt.Sprintf("Removed trigger `#%d`", id))
+}
+
+func (t triggers) autocompleteRemove(event *handler.AutocompleteEvent) error {
+	guildID := event.GuildID()
+	if guildID == nil {
+		return event.AutocompleteResult(nil)
+	}
+
+	all := make([]models.Trigger, 0)
+	if err := t.db.NewSelect().Model(&all).Where("guild_id = ?", *guildID).Order("id ASC").Limit(maxChoices).Scan(event.Ctx); err != nil {
+		return fmt.Errorf("failed to list triggers: %w", err)
+	}
+
+	choices := make([]discord.AutocompleteChoice, 0, len(all))
+	for _, trigger := range all {
+		name := fmt.Sprintf("#%d %s", trigger.ID, trigger.Kind)
+		switch trigger.Kind {
+		case models.TriggerCrowd:
+			name += fmt.Sprintf(" at %d members", trigger.Members)
+		case models.TriggerUserJoin:
+			name += " " + memberName(event, *guildID, trigger.UserID)
+		}
+		choices = append(choices, discord.AutocompleteChoiceInt{Name: name, Value: int(trigger.ID)})
+	}
+
+	return event.AutocompleteResult(choices)
+}
+
+// memberName returns the name of a member for autocomplete choices, which can not render mentions.
+func memberName(event *handler.AutocompleteEvent, guildID, userID snowflake.ID) string {
+	if member, ok := event.Client().Caches.Member(guildID, userID); ok {
+		return member.EffectiveName()
+	}
+	return userID.String()
+}
+
+func (t triggers) respond(event *handler.CommandEvent, description string) error {
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetDescription(description)
+
+	msg := discord.NewMessageUpdateBuilder().SetEmbeds(embed.Build()).Build()
+	if _, err := event.UpdateInteractionResponse(msg); err != nil {
+		return fmt.Errorf("failed to update interaction response: %w", err)
+	}
+
+	return nil
+}
+
+var _ disruptor.Command = (*triggers)(nil)
@@ -5,12 +5,16 @@ import (
 	"github.com/disgoorg/disgo/cache"
 	"github.com/disgoorg/disgo/gateway"
 	"github.com/disgoorg/disgo/sharding"
+	"github.com/disgoorg/snowflake/v2"
 )
 
 type Config struct {
 	// 🔑 The bot token used to connect to Discord
 	Token string `env:"TOKEN,required"`
 
+	// 👑 User IDs allowed to use owner-only commands, defaults to the owners of the application
+	OwnerIDs []snowflake.ID `env:"OWNER_IDS"`
+
 	Sharding struct {
 		// 🔢 Shard ID to use
 		ShardIDs []int `env:"IDS" default:"0"`
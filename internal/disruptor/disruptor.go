@@ -9,17 +9,24 @@ import (
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/disgo/sharding"
 	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/XanderD99/disruptor/internal/voice"
 )
 
 // Disruptor represents the Discord bot
 type Disruptor struct {
 	*bot.Client
+
+	// Voice is the voice connection budget scheduled disruptions share with the commands
+	Voice *voice.Limiter
 }
 
 type opts struct {
 	commands    []Command
 	middlewares []handler.Middleware
+	voice       *voice.Limiter
 }
 
 type optFunc func(*opts)
@@ -30,6 +37,13 @@ func WithMiddlewares(middlewares ...handler.Middleware) optFunc {
 	}
 }
 
+// WithVoiceLimiter sets the voice connection budget of the bot.
+func WithVoiceLimiter(limiter *voice.Limiter) optFunc {
+	return func(o *opts) {
+		o.voice = limiter
+	}
+}
+
 func WithCommands(commands ...Command) optFunc {
 	return func(o *opts) {
 		o.commands = commands
@@ -74,7 +88,24 @@ func New(cfg Config, optFuncs ...optFunc) (*Disruptor, error) {
 		return nil, fmt.Errorf("error while syncing commands: %w", err)
 	}
 
-	return &Disruptor{Client: c}, nil
+	return &Disruptor{Client: c, Voice: opts.voice}, nil
+}
+
+// OwnsGuild reports whether one of the shards of this process serves the
+// guild, following the (guild_id >> 22) % shard_count rule. Shards are checked
+// with their own shard count, so shards re-split by autoscaling are taken into
+// account as soon as they are opened.
+func (s *Disruptor) OwnsGuild(guildID snowflake.ID) bool {
+	if s.ShardManager == nil {
+		return true // a single gateway serves every guild
+	}
+
+	for shard := range s.ShardManager.Shards() {
+		if sharding.ShardIDByGuild(guildID, shard.ShardCount()) == shard.ShardID() {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Disruptor) Open(ctx context.Context) error {
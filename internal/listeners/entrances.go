@@ -0,0 +1,135 @@
+package listeners
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/internal/voice"
+)
+
+// entranceTimeout bounds playing an entrance sound.
+const entranceTimeout = time.Minute
+
+// Entrances plays the entrance sound of members joining a voice channel.
+func Entrances(l *slog.Logger, session *disruptor.Disruptor, db *bun.DB) func(*events.GuildVoiceStateUpdate) {
+	return func(e *events.GuildVoiceStateUpdate) {
+		old, state := e.OldVoiceState, e.VoiceState
+
+		// the voice connection waits for gateway events, so the sound can not block the listener
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), entranceTimeout)
+			defer cancel()
+
+			if err := playEntrance(ctx, session, db, old, state); err != nil {
+				l.Error("Failed to play entrance sound", slog.Group("guild", slog.String("id", state.GuildID.String())), slog.Any("user.id", state.UserID), slog.Any("error", err))
+			}
+		}()
+	}
+}
+
+// playEntrance plays the entrance sound of the member of state when they
+// joined or moved to a voice channel, the guild has entrance sounds enabled
+// and the cooldown of the member is over.
+func playEntrance(ctx context.Context, session *disruptor.Disruptor, db *bun.DB, old, state discord.VoiceState) error {
+	if state.ChannelID == nil || state.UserID == session.ID() {
+		return nil
+	}
+	if old.ChannelID != nil && *old.ChannelID == *state.ChannelID {
+		return nil // muted, deafened or started streaming
+	}
+
+	// the bot is disrupting the guild already
+	if _, ok := session.Caches.VoiceState(state.GuildID, session.ID()); ok {
+		return nil
+	}
+
+	entrance := models.Entrance{GuildID: state.GuildID, UserID: state.UserID}
+	if err := db.NewSelect().Model(&entrance).WherePK().Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to find entrance: %w", err)
+	}
+
+	guild := models.Guild{ID: state.GuildID}
+	if err := db.NewSelect().Model(&guild).WherePK().Relation("QuietHours").Scan(ctx); err != nil {
+		return fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	now := time.Now()
+	cooldown, minimum := guild.EntranceRules()
+	if !guild.Entrances || guild.Paused(now) || guild.Quiet(now) || !entrance.Ready(now, cooldown) {
+		return nil
+	}
+
+	channel, ok := session.Caches.GuildVoiceChannel(*state.ChannelID)
+	if !ok {
+		return nil // stage channels and channels missing from the cache
+	}
+	members := util.Filter(session.Caches.AudioChannelMembers(channel), func(m discord.Member) bool { return !m.User.Bot })
+	if len(members) < minimum {
+		return nil
+	}
+
+	sound, ok := session.Caches.GuildSoundboardSound(guild.ID, entrance.SoundID)
+	if !ok {
+		session.Logger.DebugContext(ctx, "Entrance sound no longer exists", slog.Any("guild.id", guild.ID), slog.Any("sound.id", entrance.SoundID))
+		return nil
+	}
+
+	me, ok := session.Caches.Member(guild.ID, session.ID())
+	if !ok || !util.HasVoicePermissions(session.Caches.MemberPermissionsInChannel(channel, me)) {
+		return nil
+	}
+
+	// a trigger or scheduled disruption is playing, the cooldown does not start
+	if session.Voice != nil {
+		_, unlock, ok := session.Voice.LockGuild(ctx, guild.ID)
+		if !ok {
+			return nil
+		}
+		defer unlock()
+	}
+
+	// start the cooldown first, so a member hopping between channels hears it once
+	res, err := db.NewUpdate().Model((*models.Entrance)(nil)).
+		Set("last_played = ?", now.UTC()).
+		Where("guild_id = ? AND user_id = ?", entrance.GuildID, entrance.UserID).
+		Where("last_played IS NULL OR last_played <= ?", now.Add(-cooldown).UTC()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update entrance: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return err
+	}
+
+	// an entrance sound played late is no entrance, skip it when the voice budget is full
+	if session.Voice != nil {
+		release, err := session.Voice.TryAcquire()
+		if errors.Is(err, voice.ErrSaturated) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reserve voice connection: %w", err)
+		}
+		defer release()
+	}
+
+	if err := util.PlaySound(ctx, session.Client, guild.ID, channel.ID(), sound.URL()); err != nil {
+		return fmt.Errorf("failed to play sound: %w", err)
+	}
+
+	return nil
+}
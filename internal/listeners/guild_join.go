@@ -33,7 +33,7 @@ func GuildJoin(l *slog.Logger, db *bun.DB, m *scheduler.Manager) func(*events.Gu
 		})
 
 		errGroup.Go(func() (err error) {
-			err = m.AddScheduler(handlers.HandlerTypeRandomVoiceJoin, guild.Interval)
+			err = handlers.Schedule(ctx, m, guild)
 			return
 		})
 
@@ -2,16 +2,29 @@ package listeners
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
 	"github.com/uptrace/bun"
 
 	"github.com/XanderD99/disruptor/internal/models"
 	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
 )
 
+// guildData are the tables with a guild_id column, removed along with the guild.
+var guildData = []any{
+	(*models.QuietHours)(nil),
+	(*models.Job)(nil),
+	(*models.Strategy)(nil),
+	(*models.Trigger)(nil),
+	(*models.Member)(nil),
+	(*models.Entrance)(nil),
+}
+
 func GuildLeave(l *slog.Logger, db *bun.DB, m *scheduler.Manager) func(*events.GuildLeave) {
 	return func(gr *events.GuildLeave) {
 		l = l.With(slog.Group("guild", slog.String("id", gr.Guild.ID.String())))
@@ -20,8 +33,28 @@ func GuildLeave(l *slog.Logger, db *bun.DB, m *scheduler.Manager) func(*events.G
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		if _, err := db.NewDelete().Model(&models.Guild{ID: gr.Guild.ID}).Exec(ctx); err != nil {
+		handlers.Unschedule(ctx, m, gr.Guild.ID)
+
+		if err := removeGuild(ctx, db, gr.Guild.ID); err != nil {
 			l.Error("Failed to remove guild from store", slog.Any("error", err))
 		}
 	}
 }
+
+// removeGuild removes a guild and everything stored for it in one transaction.
+func removeGuild(ctx context.Context, db *bun.DB, guildID snowflake.ID) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, model := range guildData {
+			q := tx.NewDelete().Model(model).Where("guild_id = ?", guildID)
+			if _, err := q.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to remove %s of guild: %w", q.GetTableName(), err)
+			}
+		}
+
+		if _, err := tx.NewDelete().Model(&models.Guild{ID: guildID}).WherePK().Exec(ctx); err != nil {
+			return fmt.Errorf("failed to remove guild: %w", err)
+		}
+
+		return nil
+	})
+}
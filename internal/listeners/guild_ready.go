@@ -49,7 +49,7 @@ func (t guildReadyTask) Execute(ctx context.Context) error {
 		return fmt.Errorf("failed to fetch guild %s from database: %w", t.guildID, err)
 	}
 
-	if err := t.manager.AddScheduler(handlers.HandlerTypeRandomVoiceJoin, guild.Interval); err != nil {
+	if err := handlers.Schedule(ctx, t.manager, guild); err != nil {
 		return fmt.Errorf("failed to add guild %s to voice audio scheduler manager: %w", t.guildID, err)
 	}
 
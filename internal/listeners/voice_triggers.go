@@ -0,0 +1,31 @@
+package listeners
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/XanderD99/disruptor/internal/scheduler/handlers"
+)
+
+// voiceTriggerTimeout bounds a disruption started by a voice trigger, including the wait for the voice budget.
+const voiceTriggerTimeout = 3 * time.Minute
+
+// VoiceTriggers disrupts guilds when voice activity matches one of their triggers.
+func VoiceTriggers(l *slog.Logger, trigger *handlers.VoiceTrigger) func(*events.GuildVoiceStateUpdate) {
+	return func(e *events.GuildVoiceStateUpdate) {
+		old, state := e.OldVoiceState, e.VoiceState
+
+		// the voice connection waits for gateway events, so the disruption can not block the listener
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), voiceTriggerTimeout)
+			defer cancel()
+
+			if err := trigger.Handle(ctx, old, state); err != nil {
+				l.Error("Failed to handle voice triggers", slog.Group("guild", slog.String("id", state.GuildID.String())), slog.Any("error", err))
+			}
+		}()
+	}
+}
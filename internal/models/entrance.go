@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Entrance is the soundboard sound a member picked to play when they join a
+// voice channel.
+type Entrance struct {
+	Guild   Guild        `bun:"rel:belongs-to,join:guild_id=id"` // the guild the member picked the sound in
+	GuildID snowflake.ID `bun:"guild_id,pk" validate:"required"`
+	UserID  snowflake.ID `bun:"user_id,pk" validate:"required"`
+
+	SoundID    snowflake.ID `bun:"sound_id,notnull" validate:"required"` // soundboard sound to play
+	LastPlayed time.Time    `bun:"last_played,nullzero"`                 // when the sound last played
+}
+
+// Ready reports whether the cooldown of the entrance is over at now.
+func (e Entrance) Ready(now time.Time, cooldown time.Duration) bool {
+	return e.LastPlayed.IsZero() || now.Sub(e.LastPlayed) >= cooldown
+}
@@ -5,11 +5,31 @@ import (
 	"time"
 
 	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/XanderD99/disruptor/internal/util"
 )
 
 const (
 	defaultInterval = time.Hour
 	defaultChance   = 40
+
+	defaultChaosDuration    = time.Hour
+	defaultChaosInterval    = 2 * time.Minute
+	defaultChaosIntervalMax = 5 * time.Minute
+	defaultChaosChance      = 100
+
+	defaultComboMin = 2
+	defaultComboMax = 4
+	// MaxComboLength is the most sounds a combo plays
+	MaxComboLength = 10
+
+	defaultEntranceCooldown = 10 * time.Minute
+	defaultEntranceMembers  = 2
+
+	// breakerThreshold is the amount of consecutive failures that opens the circuit of a guild
+	breakerThreshold  = 3
+	breakerBackoff    = 15 * time.Minute
+	breakerMaxBackoff = 24 * time.Hour
 )
 
 func NewGuild(snowflake snowflake.ID) Guild {
@@ -24,10 +44,219 @@ type Guild struct {
 	ID       snowflake.ID  `bun:"id,pk" validate:"required"`               // snowflake ID of the guild
 	Chance   Chance        `bun:"chance" validate:"required,gt=0,lte=100"` // chance of a sound being played
 	Interval time.Duration `bun:"interval" validate:"required"`            // interval between sounds
+	Cron     string        `bun:"cron,nullzero"`                           // cron expression, replaces the interval when set
+	Timezone string        `bun:"timezone,nullzero"`                       // IANA timezone the cron expression and active hours are evaluated in
+
+	ActiveHours string `bun:"active_hours,nullzero"` // cron expression of the minutes the interval may fire in, always when empty
+
+	Distribution Distribution  `bun:"distribution,nullzero"` // how the delay between sounds is drawn
+	IntervalMax  time.Duration `bun:"interval_max,nullzero"` // upper bound of the delay for uniform distributions
+
+	Pity    Chance `bun:"pity,nullzero"`            // chance added for every consecutive miss, 0 disables pity
+	PityCap Chance `bun:"pity_cap,nullzero"`        // upper bound of the chance raised by pity, 0 means 100%
+	Misses  int    `bun:"misses,notnull,default:0"` // consecutive rolls that did not disrupt
+
+	PausedUntil time.Time    `bun:"paused_until,nullzero"` // the guild is not disrupted until this time
+	PausedBy    snowflake.ID `bun:"paused_by,nullzero"`    // user that paused the guild
+
+	ChaosUntil       time.Time     `bun:"chaos_until,nullzero"`        // chaos hour overrides the interval and chance until this time
+	ChaosDuration    time.Duration `bun:"chaos_duration,nullzero"`     // how long a chaos hour lasts
+	ChaosInterval    time.Duration `bun:"chaos_interval,nullzero"`     // shortest delay between sounds during chaos hour
+	ChaosIntervalMax time.Duration `bun:"chaos_interval_max,nullzero"` // longest delay between sounds during chaos hour
+	ChaosChance      Chance        `bun:"chaos_chance,nullzero"`       // chance of a sound being played during chaos hour
+	ChaosCron        string        `bun:"chaos_cron,nullzero"`         // cron expression starting chaos hours, in the timezone of the guild
+
+	Failures       int          `bun:"failures,notnull,default:0"` // consecutive disruptions that failed
+	BrokenUntil    time.Time    `bun:"broken_until,nullzero"`      // the circuit is open and the guild is not disrupted until this time
+	AlertChannelID snowflake.ID `bun:"alert_channel_id,nullzero"`  // text channel notified once the circuit opens
+
+	TargetID snowflake.ID `bun:"target_id,nullzero"` // member followed by the follow target strategy, they must be targetable
+
+	ComboChance Chance `bun:"combo_chance,nullzero"` // chance a voice disruption plays a combo of sounds instead of one
+	ComboMin    int    `bun:"combo_min,nullzero"`    // fewest sounds in a combo
+	ComboMax    int    `bun:"combo_max,nullzero"`    // most sounds in a combo
+
+	Entrances        bool          `bun:"entrances,notnull,default:false"` // members' entrance sounds play when they join voice
+	EntranceCooldown time.Duration `bun:"entrance_cooldown,nullzero"`      // an entrance sound of a member does not play again for this long
+	EntranceMembers  int           `bun:"entrance_members,nullzero"`       // members a channel must have, the joining member included, for entrance sounds to play
+
+	Channels   []Channel    `bun:"rel:has-many,join:id=guild_id"` // channels in the guild
+	QuietHours []QuietHours `bun:"rel:has-many,join:id=guild_id"` // windows in which the guild is not disrupted
+	Strategies []Strategy   `bun:"rel:has-many,join:id=guild_id"` // strategies the guild is disrupted with
+}
+
+// Location returns the timezone of the guild, defaulting to UTC.
+func (g Guild) Location() (*time.Location, error) {
+	if g.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(g.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", g.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Quiet reports whether t falls inside one of the guild's quiet hours. The
+// QuietHours relation must be loaded.
+func (g Guild) Quiet(t time.Time) bool {
+	if len(g.QuietHours) == 0 {
+		return false
+	}
+
+	loc, err := g.Location()
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := t.In(loc)
+	for _, q := range g.QuietHours {
+		if q.Contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// Paused reports whether the guild is paused at t, pauses end on their own.
+func (g Guild) Paused(t time.Time) bool {
+	return t.Before(g.PausedUntil)
+}
+
+// CircuitOpen reports whether the guild is held back at t after repeated failures.
+func (g Guild) CircuitOpen(t time.Time) bool {
+	return t.Before(g.BrokenUntil)
+}
+
+// Trip opens the circuit at now once the failures reach the threshold, for
+// twice as long after every failure that follows. It reports whether the
+// circuit opened for the first time.
+func (g *Guild) Trip(now time.Time) bool {
+	if g.Failures < breakerThreshold {
+		return false
+	}
+
+	backoff := breakerBackoff << min(g.Failures-breakerThreshold, 10)
+	g.BrokenUntil = now.Add(min(backoff, breakerMaxBackoff)).UTC()
+	return g.Failures == breakerThreshold
+}
+
+// Chaos reports whether a chaos hour is running at t, it ends on its own.
+func (g Guild) Chaos(t time.Time) bool {
+	return t.Before(g.ChaosUntil)
+}
 
-	Channels []Channel `bun:"rel:has-many,join:id=guild_id"` // channels in the guild
+// StartChaos starts a chaos hour at now that lasts the chaos duration of the guild.
+func (g *Guild) StartChaos(now time.Time) {
+	g.ChaosUntil = now.Add(g.ChaosLength()).UTC()
 }
 
+// ChaosLength is the duration of a chaos hour, defaulting to an hour.
+func (g Guild) ChaosLength() time.Duration {
+	if g.ChaosDuration <= 0 {
+		return defaultChaosDuration
+	}
+	return g.ChaosDuration
+}
+
+// ChaosRange returns the shortest and longest delay between sounds during chaos hour.
+func (g Guild) ChaosRange() (time.Duration, time.Duration) {
+	if g.ChaosInterval <= 0 {
+		return defaultChaosInterval, defaultChaosIntervalMax
+	}
+	return g.ChaosInterval, max(g.ChaosInterval, g.ChaosIntervalMax)
+}
+
+// ChaosOdds is the chance during chaos hour, defaulting to 100%.
+func (g Guild) ChaosOdds() Chance {
+	if g.ChaosChance <= 0 {
+		return defaultChaosChance
+	}
+	return g.ChaosChance
+}
+
+// ChanceAt is the chance of the guild at t, the chance of a running chaos
+// hour replaces the effective chance.
+func (g Guild) ChanceAt(t time.Time) Chance {
+	if g.Chaos(t) {
+		return g.ChaosOdds()
+	}
+	return g.EffectiveChance()
+}
+
+// EffectiveChance is the chance raised by pity for every consecutive miss, up to the pity cap.
+func (g Guild) EffectiveChance() Chance {
+	if g.Pity <= 0 || g.Misses <= 0 {
+		return g.Chance
+	}
+
+	limit := g.PityCap
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	chance := g.Chance + g.Pity*Chance(g.Misses)
+	if chance > limit {
+		chance = max(limit, g.Chance)
+	}
+	return chance
+}
+
+// Roll rolls the chance of the guild at t.
+func (g Guild) Roll(t time.Time) bool {
+	return Chance(util.RandomInt(1, 100)) <= g.ChanceAt(t)
+}
+
+// ComboRange is the length range of combos, defaulting to 2-4 sounds.
+func (g Guild) ComboRange() (int, int) {
+	if g.ComboMin <= 0 {
+		return defaultComboMin, defaultComboMax
+	}
+	minimum := min(g.ComboMin, MaxComboLength)
+	return minimum, min(max(minimum, g.ComboMax), MaxComboLength)
+}
+
+// ComboLength returns a random combo length in the combo range.
+func (g Guild) ComboLength() int {
+	minimum, maximum := g.ComboRange()
+	return util.RandomInt(minimum, maximum)
+}
+
+// Sounds rolls the combo chance and returns how many sounds a voice
+// disruption plays, 1 unless it hit.
+func (g Guild) Sounds() int {
+	if g.ComboChance <= 0 || Chance(util.RandomInt(1, 100)) > g.ComboChance {
+		return 1
+	}
+	return g.ComboLength()
+}
+
+// EntranceRules returns the cooldown of entrance sounds and the members a
+// channel must have for them to play, defaulting to 10 minutes and 2 members.
+func (g Guild) EntranceRules() (time.Duration, int) {
+	cooldown, members := g.EntranceCooldown, g.EntranceMembers
+	if cooldown <= 0 {
+		cooldown = defaultEntranceCooldown
+	}
+	if members <= 0 {
+		members = defaultEntranceMembers
+	}
+	return cooldown, members
+}
+
+// Distribution decides how the delay between two disruptions is drawn.
+type Distribution string
+
+const (
+	// DistributionFixed fires about once per interval, moved by a random jitter of up to a quarter of it.
+	DistributionFixed Distribution = "fixed"
+	// DistributionUniform waits a random delay between Interval and IntervalMax.
+	DistributionUniform Distribution = "uniform"
+	// DistributionExponential waits exponentially distributed delays with Interval as mean.
+	DistributionExponential Distribution = "exponential"
+)
+
 type Chance int
 
 func (c Chance) String() string {
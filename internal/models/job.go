@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Job is a disruption queued for a specific time, it runs once and is removed.
+type Job struct {
+	ID           int64        `bun:"id,pk,autoincrement"`                  // number used to cancel the job
+	GuildID      snowflake.ID `bun:"guild_id,notnull" validate:"required"` // snowflake ID of the guild
+	ChannelID    snowflake.ID `bun:"channel_id,notnull"`                   // voice channel to join
+	SoundID      snowflake.ID `bun:"sound_id,notnull"`                     // soundboard sound to play
+	RunAt        time.Time    `bun:"run_at,notnull"`                       // when the job runs
+	CreatedBy    snowflake.ID `bun:"created_by,notnull"`                   // user that queued the job
+	ClaimedUntil time.Time    `bun:"claimed_until,nullzero"`               // an instance is running the job, others may take it over once this passes
+	MissedAt     time.Time    `bun:"missed_at,nullzero"`                   // the job was found too late to run, it is kept until cancelled
+}
+
+// Missed reports whether the job missed its time and will not run.
+func (j Job) Missed() bool {
+	return !j.MissedAt.IsZero()
+}
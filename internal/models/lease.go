@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// Lease is a named lock held by one instance of the bot at a time.
+type Lease struct {
+	Name      string    `bun:"name,pk" validate:"required"` // what the lease guards
+	Holder    string    `bun:"holder,notnull"`              // instance currently holding the lease
+	ExpiresAt time.Time `bun:"expires_at,notnull"`          // the lease is free once this passes without a renewal
+}
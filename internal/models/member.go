@@ -0,0 +1,13 @@
+package models
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Member holds the settings of a member of a guild.
+type Member struct {
+	GuildID snowflake.ID `bun:"guild_id,pk" validate:"required"`
+	UserID  snowflake.ID `bun:"user_id,pk" validate:"required"`
+
+	Targetable bool `bun:"targetable,notnull,default:false"` // agreed to be followed by the follow target strategy
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// QuietHours is a window of local time in which a guild must not be disrupted.
+// Windows that end before they start wrap past midnight.
+type QuietHours struct {
+	ID int64 `bun:"id,pk,autoincrement"`
+
+	Guild   Guild        `bun:"rel:belongs-to,join:guild_id=id"` // the guild these quiet hours belong to
+	GuildID snowflake.ID `bun:"guild_id,notnull" validate:"required"`
+
+	Weekday *time.Weekday `bun:"weekday"`       // day the window starts on, every day when nil
+	Start   int           `bun:"start,notnull"` // minutes after local midnight
+	End     int           `bun:"end,notnull"`   // minutes after local midnight
+}
+
+// Contains reports whether t, in the guild's timezone, falls inside the window.
+func (q QuietHours) Contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if q.Start < q.End {
+		return q.onDay(day) && minutes >= q.Start && minutes < q.End
+	}
+
+	// wraps past midnight, or covers the whole day when start equals end
+	if q.onDay(day) && minutes >= q.Start {
+		return true
+	}
+	return q.onDay((day+6)%7) && minutes < q.End
+}
+
+func (q QuietHours) onDay(day time.Weekday) bool {
+	return q.Weekday == nil || *q.Weekday == day
+}
+
+func (q QuietHours) String() string {
+	day := "Every day"
+	if q.Weekday != nil {
+		day = q.Weekday.String()
+	}
+	return fmt.Sprintf("%s %02d:%02d–%02d:%02d", day, q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+type Schedule struct {
+	GuildID        snowflake.ID `bun:"guild_id,pk" validate:"required"` // snowflake ID of the guild
+	Key            string       `bun:"key,pk" validate:"required"`      // handler key the guild is scheduled for
+	NextFireAt     time.Time    `bun:"next_fire_at,nullzero"`           // when the guild fires next
+	LastFireAt     time.Time    `bun:"last_fire_at,nullzero"`           // when the guild last fired
+	DeferredFireAt time.Time    `bun:"deferred_fire_at,nullzero"`       // fire time a staggered guild was pushed back from, the fire after next follows from it
+}
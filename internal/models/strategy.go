@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Strategy enables a disruption strategy in a guild. Every due guild is
+// disrupted by one of its strategies, picked by weight.
+type Strategy struct {
+	Guild   Guild        `bun:"rel:belongs-to,join:guild_id=id"` // the guild the strategy is enabled in
+	GuildID snowflake.ID `bun:"guild_id,pk" validate:"required"`
+
+	Name   string `bun:"name,pk" validate:"required"` // name of the strategy in the registry
+	Weight int    `bun:"weight,notnull,default:1"`    // chance of being picked relative to the other strategies
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// TriggerKind is the voice activity a trigger reacts to.
+type TriggerKind string
+
+const (
+	// TriggerCrowd fires when a voice channel reaches Members members.
+	TriggerCrowd TriggerKind = "crowd"
+	// TriggerUserJoin fires when UserID joins a voice channel.
+	TriggerUserJoin TriggerKind = "user_join"
+	// TriggerAFKReturn fires when someone comes back from the AFK channel.
+	TriggerAFKReturn TriggerKind = "afk_return"
+)
+
+// Trigger disrupts a guild on voice activity instead of on a schedule.
+type Trigger struct {
+	ID int64 `bun:"id,pk,autoincrement"`
+
+	Guild   Guild        `bun:"rel:belongs-to,join:guild_id=id"` // the guild this trigger belongs to
+	GuildID snowflake.ID `bun:"guild_id,notnull" validate:"required"`
+
+	Kind    TriggerKind  `bun:"kind,notnull" validate:"required"`
+	Members int          `bun:"members,nullzero"` // members a channel must reach, for crowd triggers
+	UserID  snowflake.ID `bun:"user_id,nullzero"` // member to wait for, for user join triggers
+
+	Chance    Chance        `bun:"chance,notnull"`      // chance of disrupting once the trigger matches
+	Cooldown  time.Duration `bun:"cooldown,notnull"`    // the trigger does not fire again for this long
+	LastFired time.Time     `bun:"last_fired,nullzero"` // when the trigger last disrupted the guild
+}
+
+// Ready reports whether the cooldown of the trigger is over at now.
+func (t Trigger) Ready(now time.Time) bool {
+	return t.LastFired.IsZero() || now.Sub(t.LastFired) >= t.Cooldown
+}
+
+// Roll rolls the chance of the trigger.
+func (t Trigger) Roll() bool {
+	return Chance(util.RandomInt(1, 100)) <= t.Chance
+}
+
+func (t Trigger) String() string {
+	var when string
+	switch t.Kind {
+	case TriggerCrowd:
+		when = fmt.Sprintf("a voice channel reaches %d members", t.Members)
+	case TriggerUserJoin:
+		when = fmt.Sprintf("<@%s> joins a voice channel", t.UserID)
+	case TriggerAFKReturn:
+		when = "someone returns from AFK"
+	default:
+		when = string(t.Kind)
+	}
+	return fmt.Sprintf("When %s: %s chance, %s cooldown", when, t.Chance, t.Cooldown)
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and creates timers, so schedulers can run on a fake
+// clock in tests instead of waiting in real time.
+type Clock interface {
+	Now() time.Time
+	// NewTimer creates a timer that sends the current time on its channel after d.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc calls f in its own goroutine after d.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the subset of time.Timer used by the scheduler.
+type Timer interface {
+	// C returns the channel the timer fires on, it is nil for AfterFunc timers.
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// SystemClock returns the Clock backed by the time package.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type clockKey struct{}
+
+func addClockToContext(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// Now returns the time on the clock of the scheduler or dispatcher whose
+// handler ctx belongs to, or of the Manager.EventContext ctx was derived from.
+// It returns the system time with any other ctx.
+func Now(ctx context.Context) time.Time {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok && clock != nil {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
+// Sleep waits for d on the clock Now reads from ctx, it returns the error of ctx
+// when ctx is done first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	clock, ok := ctx.Value(clockKey{}).(Clock)
+	if !ok || clock == nil {
+		clock = SystemClock()
+	}
+
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// ⏭️ What to do with disruptions missed while the bot was offline (fire, skip, delay)
+	CatchUp CatchUp `env:"CATCHUP" default:"delay"`
+	// 🎲 Maximum random delay for missed disruptions when catch up is set to delay
+	CatchUpDelay time.Duration `env:"CATCHUP_DELAY" default:"10m"`
+	// 🧹 How often schedulers are reconciled against the guilds in the database
+	ReconcileInterval time.Duration `env:"RECONCILE_INTERVAL" default:"15m"`
+	// ⌛ Maximum time a scheduler handler may run, 0 disables the timeout
+	HandlerTimeout time.Duration `env:"HANDLER_TIMEOUT" default:"5m"`
+	// 🚦 What to do when a guild is due while its previous tick still runs (skip, queue, concurrent)
+	Overlap Overlap `env:"OVERLAP" default:"skip"`
+	// 🌊 How guilds that are due at the same time are disrupted: all at once (burst) or spread out at random (stagger)
+	Dispatch Dispatch `env:"DISPATCH" default:"burst"`
+	// 📏 Share of the time until their next disruption that guilds are spread over when dispatch is stagger (0-1)
+	StaggerWindow float64 `env:"STAGGER_WINDOW" default:"0.1"`
+	// 🔒 How long the scheduler lease stays valid without a heartbeat, only the holder runs schedulers (0 disables the lease)
+	LeaseTTL time.Duration `env:"LEASE_TTL" default:"30s"`
+}
+
+func (c Config) ToManagerOpts() []Option[Manager] {
+	return []Option[Manager]{
+		WithCatchUp(c.CatchUp, c.CatchUpDelay),
+	}
+}
+
+func (c Config) ToSchedulerOpts() []Option[Scheduler] {
+	return []Option[Scheduler]{
+		WithHandlerTimeout(c.HandlerTimeout),
+		WithOverlap(c.Overlap),
+		WithDispatch(c.Dispatch, c.StaggerWindow),
+	}
+}
+
+// CatchUp decides what happens to fire times that passed while the bot was offline.
+type CatchUp int
+
+const (
+	// CatchUpDelay fires missed guilds after a random delay.
+	CatchUpDelay CatchUp = iota
+	// CatchUpFire fires missed guilds immediately.
+	CatchUpFire
+	// CatchUpSkip drops missed fires and waits for the next window.
+	CatchUpSkip
+)
+
+func (c CatchUp) String() string {
+	switch c {
+	case CatchUpFire:
+		return "fire"
+	case CatchUpSkip:
+		return "skip"
+	default:
+		return "delay"
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *CatchUp) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "delay", "":
+		*c = CatchUpDelay
+	case "fire":
+		*c = CatchUpFire
+	case "skip":
+		*c = CatchUpSkip
+	default:
+		return fmt.Errorf("invalid catch up policy %q, must be one of fire, skip or delay", text)
+	}
+	return nil
+}
+
+// Overlap decides what happens to a guild that is due while the handler of its
+// previous tick is still running. Other due guilds are not held up by it.
+type Overlap int
+
+const (
+	// OverlapSkip drops the guild from the tick, it waits for its next fire time.
+	OverlapSkip Overlap = iota
+	// OverlapQueue handles the guild once its running tick finishes.
+	OverlapQueue
+	// OverlapConcurrent handles the guild right away next to its running tick.
+	OverlapConcurrent
+)
+
+func (o Overlap) String() string {
+	switch o {
+	case OverlapQueue:
+		return "queue"
+	case OverlapConcurrent:
+		return "concurrent"
+	default:
+		return "skip"
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (o *Overlap) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "skip", "":
+		*o = OverlapSkip
+	case "queue":
+		*o = OverlapQueue
+	case "concurrent":
+		*o = OverlapConcurrent
+	default:
+		return fmt.Errorf("invalid overlap policy %q, must be one of skip, queue or concurrent", text)
+	}
+	return nil
+}
+
+// Dispatch decides how guilds that are due at the same time are disrupted.
+type Dispatch int
+
+const (
+	// DispatchBurst hands every due guild to the handler at once.
+	DispatchBurst Dispatch = iota
+	// DispatchStagger spreads due guilds over a window with random offsets,
+	// smoothing voice state traffic and REST calls.
+	DispatchStagger
+)
+
+func (d Dispatch) String() string {
+	if d == DispatchStagger {
+		return "stagger"
+	}
+	return "burst"
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Dispatch) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "burst", "":
+		*d = DispatchBurst
+	case "stagger":
+		*d = DispatchStagger
+	default:
+		return fmt.Errorf("invalid dispatch mode %q, must be one of burst or stagger", text)
+	}
+	return nil
+}
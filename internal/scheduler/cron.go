@@ -0,0 +1,266 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a calendar Timing based on a standard five field cron expression
+// (minute hour day-of-month month day-of-week), evaluated in a timezone.
+type Cron struct {
+	expr string
+	loc  *time.Location
+
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+
+	interval time.Duration // mean time between fires, see Interval
+}
+
+// cronSampleFires is how many fires the nominal interval of an expression is averaged over.
+const cronSampleFires = 64
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{min: 0, max: 59}
+	cronHour   = cronField{min: 0, max: 23}
+	cronDom    = cronField{min: 1, max: 31}
+	cronMonth  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression. Fields support lists (1,2), ranges (1-5),
+// steps (*/15, 18-23/2) and month or weekday names. A nil location means UTC.
+func ParseCron(expr string, loc *time.Location) (*Cron, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	spec := strings.TrimSpace(strings.ToLower(expr))
+	if macro, ok := cronMacros[spec]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	c := &Cron{expr: strings.TrimSpace(expr), loc: loc}
+
+	var err error
+	if c.minute, err = cronMinute.parse(fields[0]); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if c.hour, err = cronHour.parse(fields[1]); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if c.dom, err = cronDom.parse(fields[2]); err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %w", err)
+	}
+	if c.month, err = cronMonth.parse(fields[3]); err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	if c.dow, err = cronDow.parse(fields[4]); err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %w", err)
+	}
+
+	if c.dow&(1<<7) != 0 { // 7 is an alias for sunday
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*" || fields[2] == "?"
+	c.dowAny = fields[4] == "*" || fields[4] == "?"
+
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never matches", expr)
+	}
+	c.interval = c.meanGap()
+
+	return c, nil
+}
+
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			start, end = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = f.value(lo); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(hi); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if start, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			end = start
+			if hasStep {
+				end = f.max
+			}
+		}
+
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q", rangePart)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[s]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next implements Timing. It returns the first matching minute after last, or
+// the zero time if the expression never matches in the next five years.
+func (c *Cron) Next(last time.Time) time.Time {
+	t := last.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for c.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !c.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for c.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for c.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// Interval implements Timing. Calendar schedules have no fixed interval, so
+// expressions are grouped by the mean time between their fires.
+func (c *Cron) Interval() time.Duration {
+	return c.interval
+}
+
+// Matches reports whether the minute of t matches the expression.
+func (c *Cron) Matches(t time.Time) bool {
+	t = t.In(c.loc)
+	return c.month&(1<<uint(t.Month())) != 0 && c.dayMatches(t) && c.hour&(1<<uint(t.Hour())) != 0 && c.minute&(1<<uint(t.Minute())) != 0
+}
+
+// meanGap averages the time between the first fires of the expression after a
+// fixed date, rounded to the minute, so it is the same on every instance.
+func (c *Cron) meanGap() time.Duration {
+	first := c.Next(time.Date(2000, time.January, 1, 0, 0, 0, 0, c.loc))
+	if first.IsZero() {
+		return 0
+	}
+
+	last, n := first, 0
+	for ; n < cronSampleFires; n++ {
+		next := c.Next(last)
+		if next.IsZero() {
+			break
+		}
+		last = next
+	}
+	if n == 0 {
+		return 0
+	}
+
+	return (last.Sub(first) / time.Duration(n)).Round(time.Minute)
+}
+
+// Location returns the timezone the expression is evaluated in.
+func (c *Cron) Location() *time.Location {
+	return c.loc
+}
+
+func (c *Cron) String() string {
+	if c.loc == time.UTC {
+		return c.expr
+	}
+	return fmt.Sprintf("%s (%s)", c.expr, c.loc)
+}
+
+// dayMatches follows cron semantics: when both day fields are restricted a day
+// matches if either of them does.
+func (c *Cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
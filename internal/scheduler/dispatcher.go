@@ -0,0 +1,340 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/pkg/logging"
+)
+
+const (
+	// defaultJobPoll is how often the jobs table is checked when no job is due
+	// sooner, it picks up jobs queued by other instances.
+	defaultJobPoll = time.Minute
+	// jobMaxLateness marks jobs that are due for longer than this as missed, a
+	// prank queued for 21:30 should not go off the next morning after an outage.
+	jobMaxLateness = 15 * time.Minute
+	// jobClaimTTL is how long a claimed job is left to the instance running it,
+	// the job runs again elsewhere when that instance dies before finishing it.
+	jobClaimTTL = 10 * time.Minute
+	// jobTimeout is how long a job may run, it is well under jobClaimTTL so the
+	// claim of a running job does not expire.
+	jobTimeout = 5 * time.Minute
+)
+
+// JobFunc runs a one-shot job.
+type JobFunc func(ctx context.Context, job models.Job) error
+
+// JobFilter reports whether the jobs of a guild run on this instance.
+type JobFilter func(guildID snowflake.ID) bool
+
+// Dispatcher runs the one-shot jobs in the jobs table when they are due. A job
+// is claimed before it runs, so only one instance runs it even when several
+// share the table, and removed once it finished. Jobs interrupted by a
+// shutdown or a lost lease are released and run again later.
+type Dispatcher struct {
+	db      *bun.DB
+	logger  *slog.Logger
+	clock   Clock
+	poll    time.Duration
+	handler JobFunc
+	filter  JobFilter
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	wakeCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(db *bun.DB, opts ...Option[Dispatcher]) *Dispatcher {
+	d := &Dispatcher{
+		db:     db,
+		logger: slog.Default(),
+		clock:  SystemClock(),
+		poll:   defaultJobPoll,
+		wakeCh: make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+func WithDispatcherLogger(logger *slog.Logger) Option[Dispatcher] {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// WithDispatcherClock sets the clock used to wait for jobs.
+func WithDispatcherClock(clock Clock) Option[Dispatcher] {
+	return func(d *Dispatcher) {
+		d.clock = clock
+	}
+}
+
+// WithJobPoll sets how often the jobs table is checked when no job is due sooner.
+func WithJobPoll(poll time.Duration) Option[Dispatcher] {
+	return func(d *Dispatcher) {
+		d.poll = poll
+	}
+}
+
+// RegisterHandler sets the function that runs the jobs, it must be called before Start.
+func (d *Dispatcher) RegisterHandler(handler JobFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handler = handler
+}
+
+// RegisterFilter limits the dispatcher to the jobs of the guilds filter
+// accepts, the other jobs are left for the instance serving their guild.
+func (d *Dispatcher) RegisterFilter(filter JobFilter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.filter = filter
+}
+
+// Start starts dispatching jobs in the background.
+func (d *Dispatcher) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		return nil // already running
+	}
+	if d.handler == nil {
+		return fmt.Errorf("no job handler registered")
+	}
+
+	ctx = logging.AddToContext(ctx, d.logger)
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = make(chan struct{})
+
+	d.logger.InfoContext(ctx, "starting job dispatcher")
+	go d.loop(ctx, d.done)
+
+	return nil
+}
+
+// Suspend stops dispatching and waits for the running jobs, a later Start
+// picks up the jobs that are left.
+func (d *Dispatcher) Suspend() {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+
+	if cancel == nil {
+		return // not started
+	}
+
+	cancel()
+	<-done
+	d.wg.Wait()
+
+	d.logger.Info("job dispatcher suspended")
+}
+
+// Stop stops dispatching and waits for the running jobs.
+func (d *Dispatcher) Stop() error {
+	d.Suspend()
+	return nil
+}
+
+// Schedule queues a job, the ID of job is set once it is stored.
+func (d *Dispatcher) Schedule(ctx context.Context, job *models.Job) error {
+	job.RunAt = job.RunAt.UTC()
+	if _, err := d.db.NewInsert().Model(job).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to schedule job: %w", err)
+	}
+
+	d.wake()
+	return nil
+}
+
+// Cancel removes a pending job of a guild, it reports whether the job existed.
+func (d *Dispatcher) Cancel(ctx context.Context, guildID snowflake.ID, id int64) (bool, error) {
+	res, err := d.db.NewDelete().Model((*models.Job)(nil)).Where("id = ? AND guild_id = ?", id, guildID).Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job %d: %w", id, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job %d: %w", id, err)
+	}
+
+	return affected > 0, nil
+}
+
+// Pending returns the jobs of a guild that did not run yet, soonest first.
+// Missed jobs are included until they are cancelled.
+func (d *Dispatcher) Pending(ctx context.Context, guildID snowflake.ID) ([]models.Job, error) {
+	jobs := make([]models.Job, 0)
+	if err := d.db.NewSelect().Model(&jobs).Where("guild_id = ?", guildID).Order("run_at ASC", "id ASC").Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// wake makes the loop check the table again, a new job may be due before it would wake up.
+func (d *Dispatcher) wake() {
+	select {
+	case d.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	timer := d.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+		case <-d.wakeCh:
+		}
+
+		timer.Reset(d.dispatch(ctx))
+	}
+}
+
+// dispatch runs the jobs that are due and returns how long to wait for the next one.
+func (d *Dispatcher) dispatch(ctx context.Context) time.Duration {
+	now := d.clock.Now()
+	wait := d.poll
+
+	d.mu.Lock()
+	handler, filter := d.handler, d.filter
+	d.mu.Unlock()
+
+	jobs := make([]models.Job, 0)
+	err := d.db.NewSelect().Model(&jobs).
+		Where("run_at <= ?", now.Add(d.poll).UTC()).
+		Where("missed_at IS NULL").
+		Where("claimed_until IS NULL OR claimed_until < ?", now.UTC()).
+		Order("run_at ASC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "failed to find due jobs", slog.Any("error", err))
+		return wait
+	}
+
+	for _, job := range jobs {
+		if filter != nil && !filter(job.GuildID) {
+			continue
+		}
+
+		if until := job.RunAt.Sub(now); until > 0 {
+			return min(wait, until)
+		}
+
+		logger := d.logger.With(slog.Int64("job.id", job.ID), slog.Any("guild.id", job.GuildID))
+
+		if late := now.Sub(job.RunAt); late > jobMaxLateness {
+			if err := d.miss(ctx, job.ID, now); err != nil {
+				logger.ErrorContext(ctx, "failed to mark job as missed", slog.Any("error", err))
+				continue
+			}
+			logger.WarnContext(ctx, "job missed its time, keeping it as missed", slog.Duration("late", late))
+			continue
+		}
+
+		claimed, err := d.claim(ctx, job.ID, now)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to claim job", slog.Any("error", err))
+			continue
+		}
+		if !claimed {
+			continue // cancelled or taken by another instance
+		}
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.run(ctx, logger, handler, job)
+		}()
+	}
+
+	return wait
+}
+
+// run runs a claimed job. The job is removed once it finished, or released
+// when the dispatcher stopped before it did so it runs again later.
+func (d *Dispatcher) run(ctx context.Context, logger *slog.Logger, handler JobFunc, job models.Job) {
+	run := Timeout(jobTimeout, d.clock)(Recover(func(ctx context.Context) error {
+		return handler(ctx, job)
+	}))
+	err := run(addClockToContext(ctx, d.clock))
+
+	// ctx is cancelled once the dispatcher stops, the job is updated regardless
+	storeCtx, storeCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer storeCancel()
+
+	if ctx.Err() != nil {
+		if err := d.release(storeCtx, job.ID); err != nil {
+			logger.ErrorContext(storeCtx, "failed to release interrupted job", slog.Any("error", err))
+			return
+		}
+		logger.WarnContext(storeCtx, "job interrupted, it runs again later")
+		return
+	}
+
+	if _, err := d.db.NewDelete().Model((*models.Job)(nil)).Where("id = ?", job.ID).Exec(storeCtx); err != nil {
+		logger.ErrorContext(storeCtx, "failed to remove finished job", slog.Any("error", err))
+	}
+
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to run job", slog.Any("error", err))
+		return
+	}
+	logger.InfoContext(ctx, "ran job")
+}
+
+// claim marks a job as running until its claim expires, only the caller that
+// claimed it runs it.
+func (d *Dispatcher) claim(ctx context.Context, id int64, now time.Time) (bool, error) {
+	res, err := d.db.NewUpdate().Model((*models.Job)(nil)).
+		Set("claimed_until = ?", now.Add(jobClaimTTL).UTC()).
+		Where("id = ?", id).
+		Where("missed_at IS NULL").
+		Where("claimed_until IS NULL OR claimed_until < ?", now.UTC()).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// release gives up the claim of a job that did not finish.
+func (d *Dispatcher) release(ctx context.Context, id int64) error {
+	_, err := d.db.NewUpdate().Model((*models.Job)(nil)).Set("claimed_until = NULL").Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+// miss marks a job as missed, it stays listed until it is cancelled.
+func (d *Dispatcher) miss(ctx context.Context, id int64, now time.Time) error {
+	_, err := d.db.NewUpdate().Model((*models.Job)(nil)).Set("missed_at = ?", now.UTC()).Where("id = ?", id).Where("missed_at IS NULL").Exec(ctx)
+	return err
+}
@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XanderD99/disruptor/internal/models"
+)
+
+func newTestDispatcher(t *testing.T, clock *FakeClock, handler JobFunc) *Dispatcher {
+	t.Helper()
+
+	d := NewDispatcher(openTestDB(t), WithDispatcherClock(clock), WithDispatcherLogger(slog.New(slog.DiscardHandler)))
+	d.RegisterHandler(handler)
+	t.Cleanup(func() { _ = d.Stop() })
+	return d
+}
+
+func pending(t *testing.T, d *Dispatcher) []models.Job {
+	t.Helper()
+
+	jobs, err := d.Pending(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jobs
+}
+
+func TestDispatcherKeepsInterruptedJobs(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	var calls atomic.Int32
+	runs := make(chan struct{}, 4)
+	d := newTestDispatcher(t, clock, func(ctx context.Context, _ models.Job) error {
+		runs <- struct{}{}
+		if calls.Add(1) == 1 {
+			<-ctx.Done() // interrupted by Suspend
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := d.Schedule(ctx, &models.Job{GuildID: 1, RunAt: epoch.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-runs
+
+	d.Suspend()
+	if jobs := pending(t, d); len(jobs) != 1 || !jobs[0].ClaimedUntil.IsZero() {
+		t.Fatalf("pending jobs are %+v, want the interrupted job released", jobs)
+	}
+
+	// the next start runs the job again and removes it once it finished
+	if err := d.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-runs
+	eventually(t, func() bool { return len(pending(t, d)) == 0 })
+}
+
+func TestDispatcherKeepsMissedJobs(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	var calls atomic.Int32
+	d := newTestDispatcher(t, clock, func(context.Context, models.Job) error {
+		calls.Add(1)
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := d.Schedule(ctx, &models.Job{GuildID: 1, RunAt: epoch.Add(-jobMaxLateness - time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	eventually(t, func() bool {
+		jobs := pending(t, d)
+		return len(jobs) == 1 && jobs[0].Missed()
+	})
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	clock.BlockUntil(1)
+	if calls.Load() != 0 {
+		t.Fatal("missed job ran")
+	}
+}
+
+func TestDispatcherTimesOutJobsBeforeTheirClaimExpires(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	started := make(chan struct{}, 1)
+	causes := make(chan error, 1)
+	d := newTestDispatcher(t, clock, func(ctx context.Context, _ models.Job) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return ctx.Err()
+	})
+
+	ctx := context.Background()
+	if err := d.Schedule(ctx, &models.Job{GuildID: 1, RunAt: epoch.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-started
+
+	deadline := epoch.Add(time.Minute + jobTimeout)
+	if jobs := pending(t, d); len(jobs) != 1 || !jobs[0].ClaimedUntil.After(deadline) {
+		t.Fatalf("pending jobs are %+v, want a claim outlasting the timeout at %s", jobs, deadline)
+	}
+
+	clock.BlockUntil(2)
+	clock.Advance(jobTimeout)
+	if cause := <-causes; !errors.Is(cause, ErrHandlerTimeout) {
+		t.Fatalf("job was cancelled by %v, want %v", cause, ErrHandlerTimeout)
+	}
+	eventually(t, func() bool { return len(pending(t, d)) == 0 })
+}
+
+func TestDispatcherJobsSleepOnItsClock(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	slept := make(chan error, 1)
+	d := newTestDispatcher(t, clock, func(ctx context.Context, _ models.Job) error {
+		err := Sleep(ctx, time.Second)
+		slept <- err
+		return err
+	})
+
+	ctx := context.Background()
+	if err := d.Schedule(ctx, &models.Job{GuildID: 1, RunAt: epoch.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	clock.BlockUntil(3) // the dispatcher, the timeout of the job and its sleep
+	clock.Advance(time.Second)
+	if err := <-slept; err != nil {
+		t.Fatalf("sleep returned %v, want nil", err)
+	}
+}
@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/XanderD99/disruptor/pkg/logging"
+)
+
+// Event is something a scheduler or its handler did, see the types below.
+type Event interface {
+	// Name identifies the type of the event, e.g. in metrics.
+	Name() string
+}
+
+// SkipReason is why a due guild was not disrupted.
+type SkipReason string
+
+const (
+	SkipOverlap     SkipReason = "overlap"      // the previous tick of the guild was still running
+	SkipOtherShard  SkipReason = "other_shard"  // the guild is served by another process
+	SkipQuietHours  SkipReason = "quiet_hours"  // the guild is in its quiet hours
+	SkipPaused      SkipReason = "paused"       // the guild paused disruptions
+	SkipCircuitOpen SkipReason = "circuit_open" // the guild is held back after failures
+	SkipMissedRoll  SkipReason = "missed_roll"  // the guild missed its chance roll
+	SkipNobody      SkipReason = "nobody"       // nobody is in a voice channel the bot can join
+	SkipNoTarget    SkipReason = "no_target"    // the strategy found nothing to disrupt
+	SkipBusy        SkipReason = "busy"         // a sound is playing in the guild already
+)
+
+// TickStarted is emitted when a scheduler calls its handler.
+type TickStarted struct {
+	Key    string
+	Guilds []snowflake.ID
+	At     time.Time
+}
+
+// GuildSkipped is emitted when a due guild is not disrupted.
+type GuildSkipped struct {
+	Key     string
+	GuildID snowflake.ID
+	Reason  SkipReason
+	At      time.Time
+}
+
+// DisruptionStarted is emitted when a strategy starts disrupting a guild.
+// SoundID is the first of Sounds sounds played, both are 0 for strategies
+// that do not play sounds.
+type DisruptionStarted struct {
+	Key       string
+	GuildID   snowflake.ID
+	Strategy  string
+	ChannelID snowflake.ID
+	SoundID   snowflake.ID
+	Sounds    int
+	At        time.Time
+}
+
+// DisruptionFinished is emitted when a strategy is done disrupting a guild.
+type DisruptionFinished struct {
+	Key       string
+	GuildID   snowflake.ID
+	Strategy  string
+	ChannelID snowflake.ID
+	SoundID   snowflake.ID
+	Sounds    int
+	At        time.Time
+	Duration  time.Duration
+}
+
+// DisruptionFailed is emitted when disrupting a guild failed, before or after
+// it started.
+type DisruptionFailed struct {
+	Key      string
+	GuildID  snowflake.ID
+	Strategy string
+	At       time.Time
+	Err      error
+}
+
+func (TickStarted) Name() string        { return "tick_started" }
+func (GuildSkipped) Name() string       { return "guild_skipped" }
+func (DisruptionStarted) Name() string  { return "disruption_started" }
+func (DisruptionFinished) Name() string { return "disruption_finished" }
+func (DisruptionFailed) Name() string   { return "disruption_failed" }
+
+// Subscriber is called with every event published on a Bus. It runs on the
+// goroutine of the publisher, so slow work belongs in a goroutine of its own.
+type Subscriber func(ctx context.Context, event Event)
+
+// Bus passes the events of the schedulers of a Manager to its subscribers.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []Subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds a subscriber for the events published after it.
+func (b *Bus) Subscribe(subscriber Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+// Publish calls every subscriber with event, a panicking subscriber is logged
+// and does not stop the others.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		notify(ctx, subscriber, event)
+	}
+}
+
+func notify(ctx context.Context, subscriber Subscriber, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Recovered from panic in scheduler event subscriber", slog.String("event", event.Name()), slog.Any("panic", r), slog.String("stack", string(debug.Stack())))
+		}
+	}()
+
+	subscriber(ctx, event)
+}
+
+type busKey struct{}
+
+func addBusToContext(ctx context.Context, bus *Bus) context.Context {
+	return context.WithValue(ctx, busKey{}, bus)
+}
+
+// Publish publishes event on the bus of the scheduler whose handler ctx
+// belongs to, or of the Manager.EventContext ctx was derived from. It does
+// nothing with any other ctx.
+func Publish(ctx context.Context, event Event) {
+	if bus, ok := ctx.Value(busKey{}).(*Bus); ok && bus != nil {
+		bus.Publish(ctx, event)
+	}
+}
+
+// MetricsSubscriber counts the events by name, skip reason, strategy and scheduler.
+func MetricsSubscriber() Subscriber {
+	meter := otel.Meter(instrumentationName)
+
+	events, _ := meter.Int64Counter("scheduler.events", metric.WithDescription("Scheduler events by name"))
+	disruptions, _ := meter.Float64Histogram("scheduler.disruption.duration", metric.WithDescription("Duration of finished disruptions"), metric.WithUnit("s"))
+
+	return func(ctx context.Context, event Event) {
+		attrs := []attribute.KeyValue{attribute.String("event", event.Name())}
+
+		switch e := event.(type) {
+		case TickStarted:
+			attrs = append(attrs, attribute.String("scheduler.key", e.Key))
+		case GuildSkipped:
+			attrs = append(attrs, attribute.String("scheduler.key", e.Key), attribute.String("reason", string(e.Reason)))
+		case DisruptionStarted:
+			attrs = append(attrs, attribute.String("scheduler.key", e.Key), attribute.String("strategy", e.Strategy))
+		case DisruptionFinished:
+			attrs = append(attrs, attribute.String("scheduler.key", e.Key), attribute.String("strategy", e.Strategy))
+			disruptions.Record(ctx, e.Duration.Seconds(), metric.WithAttributes(attribute.String("scheduler.key", e.Key), attribute.String("strategy", e.Strategy)))
+		case DisruptionFailed:
+			attrs = append(attrs, attribute.String("scheduler.key", e.Key), attribute.String("strategy", e.Strategy))
+		}
+
+		events.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+}
@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a Clock that only moves when it is told to. Timers fire in
+// order of their deadline while the clock is advanced, with Now returning the
+// deadline of the timer that fires.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	seq    uint64                  // orders timers with the same deadline by when they were armed
+	timers map[*fakeTimer]struct{} // armed timers, fired and stopped timers are removed
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now, timers: make(map[*fakeTimer]struct{})}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now implements Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTimer implements Clock.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.newTimer(d, make(chan time.Time, 1), nil)
+}
+
+// AfterFunc implements Clock.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.newTimer(d, nil, f)
+}
+
+// Advance moves the clock forward by d, firing every timer that is due on the way.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	c.Set(target)
+}
+
+// Set moves the clock to t, firing every timer that is due on the way. Moving
+// the clock backwards does not fire any timers.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		next := c.earliest()
+		if next == nil || next.deadline.After(t) {
+			break
+		}
+		if next.deadline.After(c.now) {
+			c.now = next.deadline
+		}
+		next.fire(c.now)
+	}
+	c.now = t
+	c.cond.Broadcast()
+}
+
+// Timers returns the amount of armed timers.
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are armed, so a test can advance
+// the clock once a goroutine is waiting on it.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *FakeClock) newTimer(d time.Duration, ch chan time.Time, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, ch: ch, f: f}
+	t.arm(d)
+	return t
+}
+
+// earliest returns the armed timer with the earliest deadline. Must be called with the lock held.
+func (c *FakeClock) earliest() *fakeTimer {
+	var next *fakeTimer
+	for t := range c.timers {
+		if next == nil || t.deadline.Before(next.deadline) || (t.deadline.Equal(next.deadline) && t.seq < next.seq) {
+			next = t
+		}
+	}
+	return next
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	ch       chan time.Time
+	f        func()
+	deadline time.Time
+	seq      uint64
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop implements Timer, like time.Timer no stale time is received after it returns.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasArmed := t.disarm()
+	t.drain()
+	t.clock.cond.Broadcast()
+	return wasArmed
+}
+
+// Reset implements Timer.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasArmed := t.disarm()
+	t.drain()
+	t.arm(d)
+	return wasArmed
+}
+
+// arm must be called with the clock lock held, timers that are already due fire right away.
+func (t *fakeTimer) arm(d time.Duration) {
+	t.clock.seq++
+	t.deadline, t.seq = t.clock.now.Add(d), t.clock.seq
+	t.clock.timers[t] = struct{}{}
+	if d <= 0 {
+		t.fire(t.clock.now)
+	}
+	t.clock.cond.Broadcast()
+}
+
+// disarm removes the timer from the clock and reports whether it was armed.
+// Must be called with the clock lock held.
+func (t *fakeTimer) disarm() bool {
+	_, armed := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	return armed
+}
+
+// fire must be called with the clock lock held.
+func (t *fakeTimer) fire(now time.Time) {
+	t.disarm()
+	if t.f != nil {
+		go t.f()
+		return
+	}
+
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+// drain must be called with the clock lock held.
+func (t *fakeTimer) drain() {
+	if t.ch == nil {
+		return
+	}
+
+	select {
+	case <-t.ch:
+	default:
+	}
+}
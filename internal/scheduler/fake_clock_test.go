@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockFiresTimersInOrder(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	late, early := clock.NewTimer(2*time.Minute), clock.NewTimer(time.Minute)
+	fired := make(chan time.Time, 1)
+	clock.AfterFunc(90*time.Second, func() { fired <- clock.Now() })
+
+	clock.Advance(3 * time.Minute)
+
+	if got := <-early.C(); !got.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("early timer fired at %s, want %s", got, epoch.Add(time.Minute))
+	}
+	if got := <-late.C(); !got.Equal(epoch.Add(2 * time.Minute)) {
+		t.Fatalf("late timer fired at %s, want %s", got, epoch.Add(2*time.Minute))
+	}
+	<-fired
+
+	if got := clock.Now(); !got.Equal(epoch.Add(3 * time.Minute)) {
+		t.Fatalf("clock is at %s, want %s", got, epoch.Add(3*time.Minute))
+	}
+}
+
+func TestFakeClockForgetsFiredAndStoppedTimers(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	for range 100 {
+		clock.NewTimer(time.Minute)
+		clock.NewTimer(time.Hour).Stop()
+	}
+	clock.Advance(time.Minute)
+
+	timer := clock.NewTimer(time.Minute)
+	for range 100 {
+		timer.Reset(time.Minute)
+	}
+
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	if len(clock.timers) != 1 {
+		t.Fatalf("clock tracks %d timers, want only the armed one", len(clock.timers))
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/internal/voice"
+)
+
+var (
+	// ErrNoSounds is returned when a guild has no soundboard sounds to play.
+	ErrNoSounds = util.ErrNoSounds
+	// ErrMissingVoicePermissions is returned when members are in voice but the
+	// bot can not join any of their channels.
+	ErrMissingVoicePermissions = errors.New("missing permissions to join the occupied voice channels")
+
+	// errSkipped is returned by a strategy that did not disrupt the guild, it
+	// is neither a failure nor a success of the guild.
+	errSkipped = errors.New("disruption skipped")
+)
+
+// failed reports whether err is the fault of the guild, errors of the bot
+// itself such as timeouts, panics or a saturated voice budget do not count,
+// and neither do hiccups of Discord or the network.
+func failed(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, errSkipped),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, scheduler.ErrHandlerTimeout),
+		errors.Is(err, scheduler.ErrHandlerPanic),
+		errors.Is(err, voice.ErrSaturated),
+		errors.Is(err, voice.ErrGuildBusy),
+		transient(err):
+		return false
+	default:
+		return true
+	}
+}
+
+// transient reports whether err is a server error or rate limit of Discord or
+// a network error, which says nothing about the guild.
+func transient(err error) bool {
+	var restErr *rest.Error
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		status := restErr.Response.StatusCode
+		return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// recordFailure counts a failed disruption of a guild and notifies its alert
+// channel when the circuit opens for the first time. Failures of concurrent
+// disruptions are counted in the database, the circuit follows the stored count.
+func recordFailure(ctx context.Context, session *disruptor.Disruptor, db *bun.DB, guild models.Guild, cause error) error {
+	err := db.NewUpdate().Model(&guild).
+		Set("failures = failures + 1").
+		WherePK().
+		Returning("failures").
+		Scan(ctx, &guild.Failures)
+	if err != nil {
+		return fmt.Errorf("failed to record failure: %w", err)
+	}
+
+	now := scheduler.Now(ctx)
+	opened := guild.Trip(now)
+	if !guild.CircuitOpen(now) {
+		return nil
+	}
+
+	// a failure counted in the meantime sets its own, longer, backoff
+	_, err = db.NewUpdate().Model((*models.Guild)(nil)).
+		Set("broken_until = ?", guild.BrokenUntil).
+		Where("id = ? AND failures = ?", guild.ID, guild.Failures).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open circuit: %w", err)
+	}
+
+	session.Logger.WarnContext(ctx, "Holding back guild after repeated failures", slog.Any("guild.id", guild.ID), slog.Int("failures", guild.Failures), slog.Time("until", guild.BrokenUntil), slog.Any("error", cause))
+
+	if opened && guild.AlertChannelID != 0 {
+		if err := notifyOpenCircuit(ctx, session, guild, cause); err != nil {
+			return fmt.Errorf("failed to notify alert channel: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CloseCircuit forgets the failures of a guild after a disruption succeeded.
+func CloseCircuit(ctx context.Context, db *bun.DB, guildID snowflake.ID) error {
+	_, err := db.NewUpdate().Model((*models.Guild)(nil)).
+		Set("failures = 0").
+		Set("broken_until = NULL").
+		Where("id = ? AND failures > 0", guildID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to close circuit: %w", err)
+	}
+	return nil
+}
+
+func notifyOpenCircuit(ctx context.Context, session *disruptor.Disruptor, guild models.Guild, cause error) error {
+	hint := "Check that I can join your voice channels and that the server has soundboard sounds."
+	switch {
+	case errors.Is(cause, ErrNoSounds):
+		hint = "Add a sound to the soundboard of the server."
+	case errors.Is(cause, ErrMissingVoicePermissions):
+		hint = "Give me the `Connect`, `Speak` and `View Channel` permissions in your voice channels."
+	}
+
+	embed := discord.NewEmbedBuilder()
+	embed.SetColor(util.RGBToInteger(255, 215, 0))
+	embed.SetTitle("Disruptions are failing")
+	embed.SetDescription(fmt.Sprintf("The last %d disruptions failed, I will try again <t:%d:R>.\n%s\nA `/play` that works resumes disruptions right away.", guild.Failures, guild.BrokenUntil.Unix(), hint))
+
+	msg := discord.NewMessageCreateBuilder().SetEmbeds(embed.Build()).Build()
+	_, err := session.Rest.CreateMessage(guild.AlertChannelID, msg, rest.WithCtx(ctx))
+	return err
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+)
+
+func openTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file:"+filepath.Join(t.TempDir(), "disruptor.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqldb.SetMaxOpenConns(1)
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+
+	if _, err := db.NewCreateTable().Model((*models.Guild)(nil)).Exec(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSkipsLeaveTheCircuitAlone(t *testing.T) {
+	db := openTestDB(t)
+	session := &disruptor.Disruptor{Client: &bot.Client{Logger: slog.New(slog.DiscardHandler), Caches: cache.New()}}
+
+	ctx := context.Background()
+	guild := models.Guild{ID: 1}
+	if _, err := db.NewInsert().Model(&guild).Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	outcome := func(strategy string, err error) int {
+		t.Helper()
+
+		finish(ctx, session, db, guild, strategy, err)
+		if err := db.NewSelect().Model(&guild).WherePK().Scan(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return guild.Failures
+	}
+
+	outcome(StrategyVoiceJoin, ErrNoSounds)
+
+	// the bot is not in the cache, so there is no channel to react in
+	reaction := NewTextReactionStrategy(session)
+	if failures := outcome(StrategyTextReaction, reaction.Disrupt(ctx, guild)); failures != 1 {
+		t.Fatalf("guild has %d failures after a skip, want the failure before it", failures)
+	}
+
+	if failures := outcome(StrategyVoiceJoin, ErrNoSounds); failures != 2 {
+		t.Fatalf("guild has %d failures, want the skip to keep both failures", failures)
+	}
+
+	if failures := outcome(StrategyVoiceJoin, nil); failures != 0 {
+		t.Fatalf("guild has %d failures after a finished disruption, want 0", failures)
+	}
+}
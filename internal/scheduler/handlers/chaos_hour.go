@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+const HandlerTypeChaosHour = "chaos_hour"
+
+// NewChaosHourHandler starts a chaos hour in the guilds whose chaos schedule is due.
+func NewChaosHourHandler(session *disruptor.Disruptor, db *bun.DB, manager *scheduler.Manager) scheduler.HandleFunc {
+	registerHandlerSingleton(HandlerTypeChaosHour, func() any {
+		return newChaosHourHandler(session, db, manager)
+	})
+
+	cb, ok := getHandlerSingleton(HandlerTypeChaosHour).(scheduler.HandleFunc)
+	if !ok {
+		panic(fmt.Sprintf("handler %s is not a scheduler.HandleFunc", HandlerTypeChaosHour))
+	}
+	return cb
+}
+
+func newChaosHourHandler(session *disruptor.Disruptor, db *bun.DB, manager *scheduler.Manager) scheduler.HandleFunc {
+	return func(ctx context.Context) error {
+		guildIDs, ok := util.GetGuildIDsFromContext(ctx)
+		if !ok {
+			return fmt.Errorf("failed to get guild IDs from context")
+		}
+
+		guildIDs = ownedGuilds(ctx, session, guildIDs)
+		if len(guildIDs) == 0 {
+			return nil
+		}
+
+		guilds := make([]models.Guild, 0, len(guildIDs))
+		if err := db.NewSelect().Model(&guilds).Where("id IN (?)", bun.In(guildIDs)).Scan(ctx); err != nil {
+			return fmt.Errorf("failed to find guilds: %w", err)
+		}
+
+		now := scheduler.Now(ctx)
+		for _, guild := range guilds {
+			if err := StartChaos(ctx, db, manager, &guild, now); err != nil {
+				session.Logger.ErrorContext(ctx, "Failed to start chaos hour", slog.Any("guild.id", guild.ID), slog.Any("error", err))
+				continue
+			}
+			session.Logger.InfoContext(ctx, "Started chaos hour", slog.Any("guild.id", guild.ID), slog.Time("until", guild.ChaosUntil))
+		}
+
+		return nil
+	}
+}
+
+// StartChaos starts a chaos hour in a guild and reschedules it to fire at the chaos pace.
+func StartChaos(ctx context.Context, db *bun.DB, m *scheduler.Manager, guild *models.Guild, now time.Time) error {
+	guild.StartChaos(now)
+
+	if _, err := db.NewUpdate().Model(guild).Column("chaos_until").WherePK().Exec(ctx); err != nil {
+		return fmt.Errorf("failed to start chaos hour: %w", err)
+	}
+
+	return Schedule(ctx, m, *guild)
+}
+
+// ScheduleChaos places a guild in the chaos hour scheduler when it has a
+// chaos schedule and removes it otherwise.
+func ScheduleChaos(ctx context.Context, m *scheduler.Manager, guild models.Guild) error {
+	if guild.ChaosCron == "" {
+		if _, ok := m.Timing(HandlerTypeChaosHour, guild.ID); ok {
+			m.Unschedule(ctx, HandlerTypeChaosHour, guild.ID)
+		}
+		return nil
+	}
+
+	loc, err := guild.Location()
+	if err != nil {
+		return err
+	}
+
+	timing, err := scheduler.ParseCron(guild.ChaosCron, loc)
+	if err != nil {
+		return fmt.Errorf("invalid chaos schedule for guild %s: %w", guild.ID, err)
+	}
+
+	return m.Schedule(ctx, HandlerTypeChaosHour, guild.ID, timing)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// StrategyFollowTarget joins the voice channel of the target of the guild.
+const StrategyFollowTarget = "follow_target"
+
+type followTarget struct {
+	session *disruptor.Disruptor
+	db      *bun.DB
+	combo   ComboConfig
+}
+
+// NewFollowTargetStrategy returns the strategy joining the voice channel of
+// the member set with /target, it falls back to an occupied voice channel
+// when the target is not in one the bot can join.
+func NewFollowTargetStrategy(session *disruptor.Disruptor, db *bun.DB, combo ComboConfig) Strategy {
+	return followTarget{session: session, db: db, combo: combo}
+}
+
+func (followTarget) Name() string { return StrategyFollowTarget }
+
+func (followTarget) Description() string {
+	return "Joins the voice channel of the member set with /target, who must have given consent"
+}
+
+func (f followTarget) Disrupt(ctx context.Context, guild models.Guild) error {
+	channelID, err := f.targetChannel(ctx, guild)
+	if err != nil {
+		return err
+	}
+
+	if channelID == 0 {
+		return processGuild(ctx, f.session, guild, StrategyFollowTarget, guild.Sounds(), f.combo) // the target is not around, disrupt someone else
+	}
+
+	return playRandomSounds(ctx, f.session, guild, channelID, StrategyFollowTarget, guild.Sounds(), f.combo)
+}
+
+// targetChannel returns the voice channel the target of the guild is in, or 0
+// when the guild has no targetable target or the bot can not join its channel.
+func (f followTarget) targetChannel(ctx context.Context, guild models.Guild) (snowflake.ID, error) {
+	if guild.TargetID == 0 {
+		return 0, nil
+	}
+
+	targetable, err := Targetable(ctx, f.db, guild.ID, guild.TargetID)
+	if err != nil {
+		return 0, err
+	}
+	if !targetable {
+		f.session.Logger.DebugContext(ctx, "Target did not give consent", slog.Any("guild.id", guild.ID), slog.Any("user.id", guild.TargetID))
+		return 0, nil
+	}
+
+	state, ok := f.session.Caches.VoiceState(guild.ID, guild.TargetID)
+	if !ok || state.ChannelID == nil {
+		return 0, nil
+	}
+
+	channel, ok := f.session.Caches.GuildVoiceChannel(*state.ChannelID)
+	if !ok {
+		return 0, nil
+	}
+
+	member, ok := f.session.Caches.Member(guild.ID, f.session.ID())
+	if !ok || !util.HasVoicePermissions(f.session.Caches.MemberPermissionsInChannel(channel, member)) {
+		return 0, nil
+	}
+
+	return channel.ID(), nil
+}
+
+// Targetable reports whether a member agreed to be followed by the follow target strategy.
+func Targetable(ctx context.Context, db *bun.DB, guildID, userID snowflake.ID) (bool, error) {
+	member := models.Member{GuildID: guildID, UserID: userID}
+	if err := db.NewSelect().Model(&member).WherePK().Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to find member %s: %w", userID, err)
+	}
+	return member.Targetable, nil
+}
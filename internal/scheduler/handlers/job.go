@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/internal/voice"
+)
+
+// jobBusyRetry is how often a job checks whether the guild it plays in is free again.
+const jobBusyRetry = time.Second
+
+// NewJobHandler returns the handler running one-shot jobs, it plays the sound
+// of the job in its voice channel.
+func NewJobHandler(session *disruptor.Disruptor) scheduler.JobFunc {
+	return func(ctx context.Context, job models.Job) error {
+		if _, ok := session.Caches.Guild(job.GuildID); !ok {
+			return fmt.Errorf("guild %s is not available", job.GuildID)
+		}
+
+		sound, ok := session.Caches.GuildSoundboardSound(job.GuildID, job.SoundID)
+		if !ok {
+			return fmt.Errorf("sound %s no longer exists", job.SoundID)
+		}
+
+		channel, ok := session.Caches.GuildVoiceChannel(job.ChannelID)
+		if !ok {
+			return fmt.Errorf("voice channel %s no longer exists", job.ChannelID)
+		}
+
+		member, ok := session.Caches.Member(job.GuildID, session.ID())
+		if !ok {
+			return fmt.Errorf("bot is not a member of the guild %s", job.GuildID)
+		}
+
+		if !util.HasVoicePermissions(session.Caches.MemberPermissionsInChannel(channel, member)) {
+			return fmt.Errorf("missing voice permissions in channel %s", job.ChannelID)
+		}
+
+		// the job was queued for this moment, it waits for a sound playing in the guild
+		for {
+			err := playSound(ctx, session, job.GuildID, job.ChannelID, sound.URL())
+			if !errors.Is(err, voice.ErrGuildBusy) {
+				return err
+			}
+
+			if err := scheduler.Sleep(ctx, jobBusyRetry); err != nil {
+				return err
+			}
+		}
+	}
+}
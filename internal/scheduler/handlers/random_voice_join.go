@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
@@ -16,6 +17,7 @@ import (
 	"github.com/XanderD99/disruptor/internal/models"
 	"github.com/XanderD99/disruptor/internal/scheduler"
 	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/internal/voice"
 )
 
 const HandlerTypeRandomVoiceJoin = "random_voice_join"
@@ -34,53 +36,180 @@ func NewRandomVoiceJoinHandler(session *disruptor.Disruptor, db *bun.DB) schedul
 
 func newRandomVoiceJoinHandler(session *disruptor.Disruptor, db *bun.DB) scheduler.HandleFunc {
 	return func(ctx context.Context) error {
-		chance := util.RandomInt(0, 101) // Use float for better precision
-
-		interval, ok := util.GetIntervalFromContext(ctx)
+		guildIDs, ok := util.GetGuildIDsFromContext(ctx)
 		if !ok {
-			return fmt.Errorf("failed to get interval from context")
+			return fmt.Errorf("failed to get guild IDs from context")
 		}
 
-		guilds, err := getEligibleGuilds(ctx, db, interval, chance)
+		guildIDs = ownedGuilds(ctx, session, guildIDs)
+
+		guilds, err := getEligibleGuilds(ctx, db, guildIDs)
 		if err != nil {
 			return fmt.Errorf("failed to find guilds: %w", err)
 		}
 
+		guilds, err = rollGuilds(ctx, session, db, guilds)
+		if err != nil {
+			return fmt.Errorf("failed to roll guilds: %w", err)
+		}
+
 		maxWorkers := int(math.Max(1, math.Sqrt(float64(len(guilds)))))
 
 		return util.ProcessWithWorkerPool(ctx, guilds, maxWorkers, func(ctx context.Context, guild models.Guild) {
-			if err := processGuild(ctx, session, guild); err != nil {
-				session.Logger.ErrorContext(ctx, "Failed to process guild", slog.Any("guild.id", guild.ID), slog.Any("error", err))
+			if _, ok := session.Caches.Guild(guild.ID); !ok {
+				return // Skip if guild is not in cache
 			}
+
+			strategy, ok := pickStrategy(guild)
+			if !ok {
+				session.Logger.WarnContext(ctx, "No registered strategy enabled in guild", slog.Any("guild.id", guild.ID))
+				return
+			}
+
+			// workers run in their own goroutines, out of reach of the scheduler middlewares
+			process := scheduler.Recover(func(ctx context.Context) error {
+				return strategy.Disrupt(ctx, guild)
+			})
+
+			finish(ctx, session, db, guild, strategy.Name(), process(ctx))
 		})
 	}
 }
 
-func processGuild(ctx context.Context, session *disruptor.Disruptor, guild models.Guild) error {
+// finish records the outcome of a disruption in the circuit of the guild and
+// tells the event subscribers when it failed. A skipped disruption leaves the
+// circuit as it is, only a finished one closes it.
+func finish(ctx context.Context, session *disruptor.Disruptor, db *bun.DB, guild models.Guild, strategy string, err error) {
+	if errors.Is(err, errSkipped) {
+		return // the subscribers were told why already
+	}
+
+	if err != nil {
+		key, _ := util.GetSchedulerKeyFromContext(ctx)
+		scheduler.Publish(ctx, scheduler.DisruptionFailed{Key: key, GuildID: guild.ID, Strategy: strategy, At: scheduler.Now(ctx), Err: err})
+	}
+
+	switch {
+	case err == nil:
+		if guild.Failures > 0 {
+			err = CloseCircuit(ctx, db, guild.ID)
+		}
+	case failed(err):
+		if recordErr := recordFailure(ctx, session, db, guild, err); recordErr != nil {
+			err = errors.Join(err, recordErr)
+		}
+	}
+	if err != nil {
+		session.Logger.ErrorContext(ctx, "Failed to process guild", slog.Any("guild.id", guild.ID), slog.String("strategy", strategy), slog.Any("error", err))
+	}
+}
+
+// StrategyVoiceJoin joins an occupied voice channel and plays a random sound.
+const StrategyVoiceJoin = "voice_join"
+
+type voiceJoin struct {
+	session *disruptor.Disruptor
+	combo   ComboConfig
+}
+
+// NewVoiceJoinStrategy returns the strategy joining an occupied voice channel
+// to play a random sound, or a combo when the combo chance of the guild hits.
+// It is the strategy guilds start with.
+func NewVoiceJoinStrategy(session *disruptor.Disruptor, combo ComboConfig) Strategy {
+	return voiceJoin{session: session, combo: combo}
+}
+
+func (voiceJoin) Name() string { return StrategyVoiceJoin }
+
+func (voiceJoin) Description() string {
+	return "Joins an occupied voice channel and plays a random sound"
+}
+
+func (v voiceJoin) Disrupt(ctx context.Context, guild models.Guild) error {
+	return processGuild(ctx, v.session, guild, StrategyVoiceJoin, guild.Sounds(), v.combo)
+}
+
+// processGuild plays n sounds in an occupied voice channel of the guild, picked by weight.
+func processGuild(ctx context.Context, session *disruptor.Disruptor, guild models.Guild, strategy string, n int, combo ComboConfig) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	if _, ok := session.Caches.Guild(guild.ID); !ok {
-		return nil // Skip if guild is not in cache
-	}
-
 	// Get available voice channels
 	channelID, err := determineVoiceChannelID(ctx, session, guild)
 	if err != nil {
 		return fmt.Errorf("failed to get channels for guild %s: %w", guild.ID, err)
 	}
 
-	// Record voice connection attempt
+	if channelID == 0 {
+		skip(ctx, guild.ID, scheduler.SkipNobody)
+		return errSkipped // nobody to disrupt
+	}
 
-	sound, err := util.GetRandomSound(session.Client, guild.ID)
+	return playRandomSounds(ctx, session, guild, channelID, strategy, n, combo)
+}
+
+// playRandomSounds plays n random soundboard sounds of the guild in a voice
+// channel on behalf of a strategy, in one connection when n is more than 1.
+func playRandomSounds(ctx context.Context, session *disruptor.Disruptor, guild models.Guild, channelID snowflake.ID, strategy string, n int, combo ComboConfig) error {
+	sounds, err := util.GetRandomSounds(session.Client, guild.ID, n)
 	if err != nil {
-		return fmt.Errorf("failed to get random sound: %w", err)
+		return fmt.Errorf("failed to get random sounds: %w", err)
+	}
+
+	seq := combo.sequence()
+	for _, sound := range sounds {
+		seq.URLs = append(seq.URLs, sound.URL())
 	}
 
-	if err := util.PlaySound(ctx, session.Client, guild.ID, channelID, sound.URL()); err != nil {
+	if session.Voice != nil {
+		locked, unlock, ok := session.Voice.LockGuild(ctx, guild.ID)
+		if !ok {
+			skip(ctx, guild.ID, scheduler.SkipBusy)
+			return errSkipped
+		}
+		defer unlock()
+		ctx = locked // playSounds holds the guild already
+	}
+
+	key, _ := util.GetSchedulerKeyFromContext(ctx)
+	started := scheduler.Now(ctx)
+	scheduler.Publish(ctx, scheduler.DisruptionStarted{Key: key, GuildID: guild.ID, Strategy: strategy, ChannelID: channelID, SoundID: sounds[0].SoundID, Sounds: len(sounds), At: started})
+
+	if err := playSounds(ctx, session, guild.ID, channelID, seq); err != nil {
+		return err
+	}
+
+	scheduler.Publish(ctx, scheduler.DisruptionFinished{Key: key, GuildID: guild.ID, Strategy: strategy, ChannelID: channelID, SoundID: sounds[0].SoundID, Sounds: len(sounds), At: scheduler.Now(ctx), Duration: scheduler.Now(ctx).Sub(started)})
+	return nil
+}
+
+// playSound plays a sound once the voice budget has room for it, it gives up
+// when the budget stays saturated for longer than its max wait.
+func playSound(ctx context.Context, session *disruptor.Disruptor, guildID, channelID snowflake.ID, url string) error {
+	return playSounds(ctx, session, guildID, channelID, util.Sequence{URLs: []string{url}})
+}
+
+// playSounds plays a sequence of sounds in one connection, like playSound. It
+// returns voice.ErrGuildBusy when a sound is playing in the guild already.
+func playSounds(ctx context.Context, session *disruptor.Disruptor, guildID, channelID snowflake.ID, seq util.Sequence) error {
+	if session.Voice != nil {
+		_, unlock, ok := session.Voice.LockGuild(ctx, guildID)
+		if !ok {
+			return voice.ErrGuildBusy
+		}
+		defer unlock()
+
+		release, err := session.Voice.Acquire(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to reserve voice connection: %w", err)
+		}
+		defer release()
+	}
+
+	if err := util.PlaySounds(ctx, session.Client, guildID, channelID, seq); err != nil {
 		return fmt.Errorf("failed to play sound: %w", err)
 	}
 
@@ -132,7 +261,7 @@ func determineVoiceChannelID(ctx context.Context, session *disruptor.Disruptor,
 
 func getAvailableVoiceChannels(ctx context.Context, session *disruptor.Disruptor, guild models.Guild) ([]snowflake.ID, error) {
 	if session.Caches.GuildSoundboardSoundsLen(guild.ID) == 0 {
-		return nil, fmt.Errorf("there are no soundboard sounds available")
+		return nil, ErrNoSounds
 	}
 
 	channels, err := session.Rest.GetGuildChannels(guild.ID, rest.WithCtx(ctx))
@@ -146,6 +275,7 @@ func getAvailableVoiceChannels(ctx context.Context, session *disruptor.Disruptor
 	}
 
 	filtered := make([]snowflake.ID, 0)
+	forbidden := 0 // occupied channels the bot can not join
 	for _, channel := range channels {
 		if channel.Type() != discord.ChannelTypeGuildVoice {
 			continue
@@ -156,24 +286,54 @@ func getAvailableVoiceChannels(ctx context.Context, session *disruptor.Disruptor
 			continue
 		}
 
-		permissions := session.Caches.MemberPermissionsInChannel(voiceChannel, member)
-		if !util.HasVoicePermissions(permissions) {
+		if len(session.Caches.AudioChannelMembers(voiceChannel)) == 0 {
 			continue
 		}
 
-		if len(session.Caches.AudioChannelMembers(voiceChannel)) == 0 {
+		permissions := session.Caches.MemberPermissionsInChannel(voiceChannel, member)
+		if !util.HasVoicePermissions(permissions) {
+			forbidden++
 			continue
 		}
 
 		filtered = append(filtered, channel.ID())
 	}
 
+	if len(filtered) == 0 && forbidden > 0 {
+		return nil, ErrMissingVoicePermissions
+	}
+
 	return filtered, nil
 }
 
-func getEligibleGuilds(ctx context.Context, db *bun.DB, interval time.Duration, chance int) ([]models.Guild, error) {
+// skip tells the subscribers of the scheduler events why a due guild is not disrupted.
+func skip(ctx context.Context, guildID snowflake.ID, reason scheduler.SkipReason) {
+	key, _ := util.GetSchedulerKeyFromContext(ctx)
+	scheduler.Publish(ctx, scheduler.GuildSkipped{Key: key, GuildID: guildID, Reason: reason, At: scheduler.Now(ctx)})
+}
+
+// ownedGuilds drops the guilds served by the shards of another process, so
+// processes sharing a database do not disrupt the same guild.
+func ownedGuilds(ctx context.Context, session *disruptor.Disruptor, guildIDs []snowflake.ID) []snowflake.ID {
+	owned := util.Filter(guildIDs, session.OwnsGuild)
+	if skipped := len(guildIDs) - len(owned); skipped > 0 {
+		session.Logger.DebugContext(ctx, "Skipping guilds of other shards", slog.Int("skipped", skipped))
+		for _, guildID := range guildIDs {
+			if !session.OwnsGuild(guildID) {
+				skip(ctx, guildID, scheduler.SkipOtherShard)
+			}
+		}
+	}
+	return owned
+}
+
+func getEligibleGuilds(ctx context.Context, db *bun.DB, guildIDs []snowflake.ID) ([]models.Guild, error) {
+	if len(guildIDs) == 0 {
+		return nil, nil // Nothing due
+	}
+
 	guilds := make([]models.Guild, 0)
-	if err := db.NewSelect().Model(&guilds).Where("chance >= ? AND interval = ?", chance, interval).Relation("Channels").Scan(ctx); err != nil {
+	if err := db.NewSelect().Model(&guilds).Where("id IN (?)", bun.In(guildIDs)).Relation("Channels").Relation("QuietHours").Relation("Strategies").Scan(ctx); err != nil {
 		return nil, fmt.Errorf("failed to find eligible guilds: %w", err)
 	}
 
@@ -183,3 +343,185 @@ func getEligibleGuilds(ctx context.Context, db *bun.DB, interval time.Duration,
 
 	return guilds, nil
 }
+
+// holdBack reports why a guild must not be disrupted at now. Every way of
+// disrupting a guild shares these checks: quiet hours, an open circuit and a
+// pause. The QuietHours relation of the guild must be loaded.
+func holdBack(guild models.Guild, now time.Time) (scheduler.SkipReason, bool) {
+	switch {
+	case guild.Quiet(now):
+		return scheduler.SkipQuietHours, true
+	case guild.CircuitOpen(now):
+		return scheduler.SkipCircuitOpen, true
+	case guild.Paused(now):
+		return scheduler.SkipPaused, true
+	default:
+		return "", false
+	}
+}
+
+// rollGuilds rolls the chance of every guild on its own and returns the guilds
+// that hit. Guilds in quiet hours, paused or held back after failures are not
+// rolled, so they do not build up pity.
+func rollGuilds(ctx context.Context, session *disruptor.Disruptor, db *bun.DB, guilds []models.Guild) ([]models.Guild, error) {
+	now := scheduler.Now(ctx)
+
+	hits := make([]models.Guild, 0, len(guilds))
+	var hitIDs, missIDs []snowflake.ID
+	for _, guild := range guilds {
+		if reason, held := holdBack(guild, now); held {
+			session.Logger.DebugContext(ctx, "Skipping guild", slog.Any("guild.id", guild.ID), slog.String("reason", string(reason)))
+			skip(ctx, guild.ID, reason)
+			continue
+		}
+
+		if !guild.Roll(now) {
+			session.Logger.DebugContext(ctx, "Guild missed chance roll", slog.Any("guild.id", guild.ID), slog.Any("chance", guild.ChanceAt(now)), slog.Int("misses", guild.Misses+1))
+			skip(ctx, guild.ID, scheduler.SkipMissedRoll)
+			missIDs = append(missIDs, guild.ID)
+			continue
+		}
+
+		hits = append(hits, guild)
+		if guild.Misses > 0 {
+			hitIDs = append(hitIDs, guild.ID)
+		}
+	}
+
+	if len(missIDs) > 0 {
+		if _, err := db.NewUpdate().Model((*models.Guild)(nil)).Set("misses = misses + 1").Where("id IN (?)", bun.In(missIDs)).Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to record misses: %w", err)
+		}
+	}
+
+	if len(hitIDs) > 0 {
+		if _, err := db.NewUpdate().Model((*models.Guild)(nil)).Set("misses = 0").Where("id IN (?)", bun.In(hitIDs)).Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to reset misses: %w", err)
+		}
+	}
+
+	return hits, nil
+}
+
+// Timing returns the scheduler timing matching the settings of a guild, a
+// chaos hour running at now fires faster until it is over.
+func Timing(guild models.Guild, now time.Time) (scheduler.Timing, error) {
+	timing, err := baseTiming(guild)
+	if err != nil {
+		return nil, err
+	}
+
+	if guild.Chaos(now) {
+		minimum, maximum := guild.ChaosRange()
+		chaos := scheduler.Every(minimum)
+		if maximum > minimum {
+			chaos = scheduler.Uniform(minimum, maximum)
+		}
+		return scheduler.Burst(chaos, guild.ChaosUntil, timing), nil
+	}
+
+	return timing, nil
+}
+
+func baseTiming(guild models.Guild) (scheduler.Timing, error) {
+	if guild.Cron == "" && guild.ActiveHours == "" {
+		return intervalTiming(guild)
+	}
+
+	loc, err := guild.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	if guild.Cron != "" {
+		return scheduler.ParseCron(guild.Cron, loc)
+	}
+
+	timing, err := intervalTiming(guild)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := scheduler.ParseCron(guild.ActiveHours, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid active hours: %w", err)
+	}
+	return scheduler.Within(timing, window), nil
+}
+
+// intervalTiming returns the timing of the interval and distribution of a guild.
+func intervalTiming(guild models.Guild) (scheduler.Timing, error) {
+	switch guild.Distribution {
+	case models.DistributionUniform:
+		if guild.IntervalMax <= guild.Interval {
+			return nil, fmt.Errorf("invalid uniform range %s-%s", guild.Interval, guild.IntervalMax)
+		}
+		return scheduler.Uniform(guild.Interval, guild.IntervalMax), nil
+	case models.DistributionExponential:
+		return scheduler.Exponential(guild.Interval), nil
+	default:
+		return scheduler.Every(guild.Interval), nil
+	}
+}
+
+// Schedule places a guild in the random voice join scheduler matching its
+// settings, and in the chaos hour scheduler when it starts chaos hours on a schedule.
+func Schedule(ctx context.Context, m *scheduler.Manager, guild models.Guild) error {
+	timing, err := Timing(guild, m.Now())
+	if err != nil {
+		return fmt.Errorf("failed to determine timing for guild %s: %w", guild.ID, err)
+	}
+
+	if err := m.Schedule(ctx, HandlerTypeRandomVoiceJoin, guild.ID, timing); err != nil {
+		return err
+	}
+
+	return ScheduleChaos(ctx, m, guild)
+}
+
+// maxQuietLookahead bounds how many fire times NextFire skips while looking for one outside quiet hours.
+const maxQuietLookahead = 1000
+
+// NextFire returns the next fire time of a guild that falls outside its quiet
+// hours and its pause. The QuietHours relation of the guild must be loaded.
+func NextFire(m *scheduler.Manager, guild models.Guild) (time.Time, bool) {
+	next, ok := m.NextFire(HandlerTypeRandomVoiceJoin, guild.ID)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	timing, ok := m.Timing(HandlerTypeRandomVoiceJoin, guild.ID)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if guild.Paused(next) {
+		next = timing.Next(guild.PausedUntil)
+	}
+
+	for i := 0; i < maxQuietLookahead && !next.IsZero(); i++ {
+		if !guild.Quiet(next) {
+			return next, true
+		}
+		next = timing.Next(next)
+	}
+
+	return time.Time{}, false
+}
+
+// Reconciler returns the guilds in the database, schedulers drop any guild that is not in it.
+func Reconciler(db *bun.DB) scheduler.ReconcileFunc {
+	return func(ctx context.Context) ([]snowflake.ID, error) {
+		guildIDs := make([]snowflake.ID, 0)
+		if err := db.NewSelect().Model((*models.Guild)(nil)).Column("id").Scan(ctx, &guildIDs); err != nil {
+			return nil, fmt.Errorf("failed to list guilds: %w", err)
+		}
+		return guildIDs, nil
+	}
+}
+
+// Unschedule removes a guild from the random voice join and chaos hour schedulers.
+func Unschedule(ctx context.Context, m *scheduler.Manager, guildID snowflake.ID) {
+	m.Unschedule(ctx, HandlerTypeRandomVoiceJoin, guildID)
+	m.Unschedule(ctx, HandlerTypeChaosHour, guildID)
+}
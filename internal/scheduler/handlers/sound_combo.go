@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+type ComboConfig struct {
+	// ⏸️ Shortest silence between two sounds of a combo
+	GapMin time.Duration `env:"GAP_MIN" default:"500ms"`
+	// ⏯️ Longest silence between two sounds of a combo
+	GapMax time.Duration `env:"GAP_MAX" default:"3s"`
+	// ⏱️ Combos are cut off once they play this long (0 for no limit)
+	MaxDuration time.Duration `env:"MAX_DURATION" default:"1m"`
+}
+
+// sequence returns an empty sequence with the gaps and cap of the config.
+func (c ComboConfig) sequence() util.Sequence {
+	return util.Sequence{GapMin: c.GapMin, GapMax: c.GapMax, MaxDuration: c.MaxDuration}
+}
+
+// StrategySoundCombo plays a combo of sounds in an occupied voice channel.
+const StrategySoundCombo = "sound_combo"
+
+type soundCombo struct {
+	session *disruptor.Disruptor
+	combo   ComboConfig
+}
+
+// NewSoundComboStrategy returns the strategy that always plays a combo, its
+// length is drawn from the combo range of the guild.
+func NewSoundComboStrategy(session *disruptor.Disruptor, combo ComboConfig) Strategy {
+	return soundCombo{session: session, combo: combo}
+}
+
+func (soundCombo) Name() string { return StrategySoundCombo }
+
+func (soundCombo) Description() string {
+	return "Joins an occupied voice channel and plays several sounds in a row"
+}
+
+func (s soundCombo) Disrupt(ctx context.Context, guild models.Guild) error {
+	return processGuild(ctx, s.session, guild, StrategySoundCombo, guild.ComboLength(), s.combo)
+}
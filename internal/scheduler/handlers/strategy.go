@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// Strategy is a way of disrupting a guild. The random voice join scheduler
+// picks one of the strategies enabled in a due guild, by weight.
+type Strategy interface {
+	// Name identifies the strategy in the registry and the database.
+	Name() string
+	// Description is shown to admins choosing strategies.
+	Description() string
+	// Disrupt disrupts the guild once, nil means the guild was disrupted. When
+	// there is nothing to disrupt it tells the subscribers why and returns errSkipped.
+	Disrupt(ctx context.Context, guild models.Guild) error
+}
+
+// DefaultStrategy is used by guilds that did not enable any strategy.
+const DefaultStrategy = StrategyVoiceJoin
+
+var (
+	strategies   = make(map[string]Strategy)
+	strategiesMu sync.RWMutex
+)
+
+// RegisterStrategy makes a strategy available to guilds, it replaces a
+// strategy registered under the same name.
+func RegisterStrategy(strategy Strategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[strategy.Name()] = strategy
+}
+
+// GetStrategy returns the registered strategy named name.
+func GetStrategy(name string) (Strategy, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	strategy, ok := strategies[name]
+	return strategy, ok
+}
+
+// Strategies returns the registered strategies sorted by name.
+func Strategies() []Strategy {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	registered := make([]Strategy, 0, len(strategies))
+	for _, strategy := range strategies {
+		registered = append(registered, strategy)
+	}
+	slices.SortFunc(registered, func(a, b Strategy) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return registered
+}
+
+// GuildStrategies returns the strategies enabled in a guild, the default
+// strategy when it did not enable any. The Strategies relation must be loaded.
+func GuildStrategies(guild models.Guild) []models.Strategy {
+	if len(guild.Strategies) == 0 {
+		return []models.Strategy{{GuildID: guild.ID, Name: DefaultStrategy, Weight: 1}}
+	}
+	return guild.Strategies
+}
+
+// pickStrategy picks one of the registered strategies enabled in a guild by
+// weight, it reports false when none of them is registered.
+func pickStrategy(guild models.Guild) (Strategy, bool) {
+	enabled := make([]Strategy, 0)
+	weights := make([]int, 0)
+	total := 0
+	for _, s := range GuildStrategies(guild) {
+		strategy, ok := GetStrategy(s.Name)
+		if !ok || s.Weight <= 0 {
+			continue
+		}
+		enabled = append(enabled, strategy)
+		weights = append(weights, s.Weight)
+		total += s.Weight
+	}
+
+	if len(enabled) == 0 {
+		return nil, false
+	}
+
+	r := util.RandomInt(0, total-1)
+	for i, w := range weights {
+		if r < w {
+			return enabled[i], true
+		}
+		r -= w
+	}
+
+	return enabled[0], true // fallback
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// StrategyTextReaction reacts to the latest message of a text channel.
+const StrategyTextReaction = "text_reaction"
+
+// reactions are the emojis the text reaction strategy picks from.
+var reactions = []string{"👀", "🤨", "💀", "🔥", "🤡", "🫠", "📢", "🐸"}
+
+type textReaction struct {
+	session *disruptor.Disruptor
+}
+
+// NewTextReactionStrategy returns the strategy reacting with a random emoji
+// to the latest message of a random text channel.
+func NewTextReactionStrategy(session *disruptor.Disruptor) Strategy {
+	return textReaction{session: session}
+}
+
+func (textReaction) Name() string { return StrategyTextReaction }
+
+func (textReaction) Description() string {
+	return "Reacts with a random emoji to the latest message of a text channel"
+}
+
+func (t textReaction) Disrupt(ctx context.Context, guild models.Guild) error {
+	channelID, ok := t.channel(guild)
+	if !ok {
+		skip(ctx, guild.ID, scheduler.SkipNoTarget)
+		return errSkipped // no channel to react in
+	}
+
+	// the bot does not receive messages, so the cached last message ID may be stale
+	messages, err := t.session.Rest.GetMessages(channelID, 0, 0, 0, 1, rest.WithCtx(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to get latest message of channel %s: %w", channelID, err)
+	}
+	if len(messages) == 0 {
+		skip(ctx, guild.ID, scheduler.SkipNoTarget)
+		return errSkipped
+	}
+
+	key, _ := util.GetSchedulerKeyFromContext(ctx)
+	started := scheduler.Now(ctx)
+	scheduler.Publish(ctx, scheduler.DisruptionStarted{Key: key, GuildID: guild.ID, Strategy: StrategyTextReaction, ChannelID: channelID, At: started})
+
+	if err := t.session.Rest.AddReaction(channelID, messages[0].ID, util.Random(reactions), rest.WithCtx(ctx)); err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	scheduler.Publish(ctx, scheduler.DisruptionFinished{Key: key, GuildID: guild.ID, Strategy: StrategyTextReaction, ChannelID: channelID, At: scheduler.Now(ctx), Duration: scheduler.Now(ctx).Sub(started)})
+	return nil
+}
+
+// channel picks a random text channel with messages that the bot can react in.
+func (t textReaction) channel(guild models.Guild) (snowflake.ID, bool) {
+	member, ok := t.session.Caches.Member(guild.ID, t.session.ID())
+	if !ok {
+		return 0, false
+	}
+
+	channelIDs := make([]snowflake.ID, 0)
+	for channel := range t.session.Caches.ChannelsForGuild(guild.ID) {
+		text, ok := channel.(discord.GuildTextChannel)
+		if !ok || text.LastMessageID() == nil {
+			continue
+		}
+
+		permissions := t.session.Caches.MemberPermissionsInChannel(text, member)
+		if !permissions.Has(discord.PermissionViewChannel, discord.PermissionReadMessageHistory, discord.PermissionAddReactions) {
+			continue
+		}
+
+		channelIDs = append(channelIDs, text.ID())
+	}
+
+	if len(channelIDs) == 0 {
+		return 0, false
+	}
+
+	return util.Random(channelIDs), true
+}
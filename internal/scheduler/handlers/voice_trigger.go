@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/disruptor"
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/scheduler"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// HandlerTypeVoiceTrigger is the key of the events of disruptions started by voice triggers.
+const HandlerTypeVoiceTrigger = "voice_trigger"
+
+// VoiceTrigger disrupts guilds when voice activity matches one of their
+// triggers, see models.Trigger.
+type VoiceTrigger struct {
+	session *disruptor.Disruptor
+	db      *bun.DB
+	manager *scheduler.Manager
+	combo   ComboConfig
+}
+
+func NewVoiceTrigger(session *disruptor.Disruptor, db *bun.DB, manager *scheduler.Manager, combo ComboConfig) *VoiceTrigger {
+	return &VoiceTrigger{session: session, db: db, manager: manager, combo: combo}
+}
+
+// Handle fires the first matching trigger of the guild whose chance hits and
+// whose cooldown is over. Only joins and moves of members match triggers.
+func (v *VoiceTrigger) Handle(ctx context.Context, old, state discord.VoiceState) error {
+	if state.ChannelID == nil || state.UserID == v.session.ID() {
+		return nil
+	}
+	if old.ChannelID != nil && *old.ChannelID == *state.ChannelID {
+		return nil // muted, deafened or started streaming
+	}
+	if member, ok := v.session.Caches.Member(state.GuildID, state.UserID); ok && member.User.Bot {
+		return nil
+	}
+
+	// the bot is disrupting the guild already
+	if _, ok := v.session.Caches.VoiceState(state.GuildID, v.session.ID()); ok {
+		return nil
+	}
+	// hold the guild while the triggers are rolled, so concurrent updates and
+	// scheduled disruptions do not join it at the same time
+	if v.session.Voice != nil {
+		locked, unlock, ok := v.session.Voice.LockGuild(ctx, state.GuildID)
+		if !ok {
+			return nil
+		}
+		defer unlock()
+		ctx = locked
+	}
+
+	triggers := make([]models.Trigger, 0)
+	if err := v.db.NewSelect().Model(&triggers).Where("guild_id = ?", state.GuildID).Order("id ASC").Scan(ctx); err != nil {
+		return fmt.Errorf("failed to find triggers: %w", err)
+	}
+
+	now := time.Now()
+	triggers = util.Filter(triggers, func(t models.Trigger) bool {
+		return t.Ready(now) && v.matches(t, old, state)
+	})
+	if len(triggers) == 0 {
+		return nil
+	}
+
+	ctx = util.AddSchedulerKeyToContext(v.manager.EventContext(ctx), HandlerTypeVoiceTrigger)
+
+	guild := models.Guild{ID: state.GuildID}
+	if err := v.db.NewSelect().Model(&guild).WherePK().Relation("QuietHours").Scan(ctx); err != nil {
+		return fmt.Errorf("failed to find guild: %w", err)
+	}
+
+	if reason, held := holdBack(guild, now); held {
+		skip(ctx, guild.ID, reason)
+		return nil
+	}
+
+	rolled := false
+	for _, trigger := range triggers {
+		if trigger.Kind == models.TriggerUserJoin {
+			// like /target, waiting for a member needs their consent
+			targetable, err := Targetable(ctx, v.db, guild.ID, trigger.UserID)
+			if err != nil {
+				return err
+			}
+			if !targetable {
+				continue
+			}
+		}
+
+		rolled = true
+		if !trigger.Roll() {
+			continue
+		}
+
+		fired, err := v.claim(ctx, trigger, now)
+		if err != nil {
+			return err
+		}
+		if !fired {
+			continue // fired by another update in the meantime
+		}
+
+		v.session.Logger.DebugContext(ctx, "Voice trigger fired", slog.Any("guild.id", guild.ID), slog.Int64("trigger.id", trigger.ID), slog.String("trigger.kind", string(trigger.Kind)))
+
+		process := scheduler.Recover(func(ctx context.Context) error {
+			return v.disrupt(ctx, guild, *state.ChannelID)
+		})
+		finish(ctx, v.session, v.db, guild, HandlerTypeVoiceTrigger, process(ctx))
+		return nil
+	}
+
+	if rolled {
+		skip(ctx, guild.ID, scheduler.SkipMissedRoll)
+	}
+	return nil
+}
+
+// matches reports whether the move from old to state is the voice activity trigger waits for.
+func (v *VoiceTrigger) matches(trigger models.Trigger, old, state discord.VoiceState) bool {
+	switch trigger.Kind {
+	case models.TriggerCrowd:
+		channel, ok := v.session.Caches.GuildVoiceChannel(*state.ChannelID)
+		if !ok {
+			return false
+		}
+		members := util.Filter(v.session.Caches.AudioChannelMembers(channel), func(m discord.Member) bool { return !m.User.Bot })
+		return len(members) == trigger.Members
+	case models.TriggerUserJoin:
+		return state.UserID == trigger.UserID
+	case models.TriggerAFKReturn:
+		guild, ok := v.session.Caches.Guild(state.GuildID)
+		if !ok || guild.AfkChannelID == nil || old.ChannelID == nil {
+			return false
+		}
+		return *old.ChannelID == *guild.AfkChannelID && *state.ChannelID != *guild.AfkChannelID
+	default:
+		return false
+	}
+}
+
+// claim starts the cooldown of a trigger, it reports false when another
+// update started it first.
+func (v *VoiceTrigger) claim(ctx context.Context, trigger models.Trigger, now time.Time) (bool, error) {
+	res, err := v.db.NewUpdate().Model((*models.Trigger)(nil)).
+		Set("last_fired = ?", now.UTC()).
+		Where("id = ?", trigger.ID).
+		Where("last_fired IS NULL OR last_fired <= ?", now.Add(-trigger.Cooldown).UTC()).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to fire trigger %d: %w", trigger.ID, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to fire trigger %d: %w", trigger.ID, err)
+	}
+
+	return affected == 1, nil
+}
+
+// disrupt plays sounds in the channel the voice activity happened in.
+func (v *VoiceTrigger) disrupt(ctx context.Context, guild models.Guild, channelID snowflake.ID) error {
+	channel, ok := v.session.Caches.GuildVoiceChannel(channelID)
+	if !ok {
+		return errSkipped // stage channels and channels missing from the cache
+	}
+
+	member, ok := v.session.Caches.Member(guild.ID, v.session.ID())
+	if !ok {
+		return fmt.Errorf("bot is not a member of the guild %s", guild.ID)
+	}
+	if !util.HasVoicePermissions(v.session.Caches.MemberPermissionsInChannel(channel, member)) {
+		return ErrMissingVoicePermissions
+	}
+
+	return playRandomSounds(ctx, v.session, guild, channelID, HandlerTypeVoiceTrigger, guild.Sounds(), v.combo)
+}
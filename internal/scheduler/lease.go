@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/models"
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// Lease is a lock in the database that one instance holds at a time. The
+// holder renews it before it expires, once it expires another instance can
+// take it over.
+type Lease struct {
+	db     *bun.DB
+	name   string
+	holder string
+	ttl    time.Duration
+	clock  Clock
+}
+
+// NewLease returns a lease named name that expires ttl after it was last
+// acquired. Every instance needs a unique holder, see DefaultHolder.
+func NewLease(db *bun.DB, name, holder string, ttl time.Duration, opts ...Option[Lease]) *Lease {
+	l := &Lease{db: db, name: name, holder: holder, ttl: ttl, clock: SystemClock()}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// WithLeaseClock sets the clock used for the expiry of a lease.
+func WithLeaseClock(clock Clock) Option[Lease] {
+	return func(l *Lease) {
+		l.clock = clock
+	}
+}
+
+// DefaultHolder identifies this process, it stays unique when containers of
+// a rolling deploy share a hostname.
+func DefaultHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), util.RandomInt(0, 1<<20))
+}
+
+// Holder returns the holder of this instance.
+func (l *Lease) Holder() string {
+	return l.holder
+}
+
+// Acquire takes the lease when it is free or expired and renews it when this
+// instance already holds it. It reports whether this instance holds the lease.
+func (l *Lease) Acquire(ctx context.Context) (bool, error) {
+	now := l.clock.Now().UTC()
+	lease := models.Lease{Name: l.name, Holder: l.holder, ExpiresAt: now.Add(l.ttl)}
+
+	res, err := l.db.NewInsert().Model(&lease).
+		On("CONFLICT (name) DO UPDATE").
+		Set("holder = EXCLUDED.holder").
+		Set("expires_at = EXCLUDED.expires_at").
+		Where("lease.holder = EXCLUDED.holder OR lease.expires_at < ?", now).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", l.name, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", l.name, err)
+	}
+
+	return affected > 0, nil
+}
+
+// Release gives up the lease if this instance holds it, so a standby does not
+// have to wait for it to expire.
+func (l *Lease) Release(ctx context.Context) error {
+	if _, err := l.db.NewDelete().Model((*models.Lease)(nil)).Where("name = ? AND holder = ?", l.name, l.holder).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", l.name, err)
+	}
+	return nil
+}
+
+// Runner is started by an Elector while it holds the lease and suspended
+// once the lease is lost. Manager and Dispatcher are runners.
+type Runner interface {
+	Start(ctx context.Context) error
+	// Suspend stops running until the next Start.
+	Suspend()
+	Stop() error
+}
+
+// Elector runs its runners only while its lease is held. Standby instances
+// keep trying to acquire the lease and start their runners once it expires.
+type Elector struct {
+	lease   *Lease
+	runners []Runner
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stopped bool
+}
+
+func NewElector(lease *Lease, logger *slog.Logger, runners ...Runner) *Elector {
+	return &Elector{lease: lease, runners: runners, logger: logger}
+}
+
+// Start tries to acquire the lease every third of its ttl until Stop is called.
+func (e *Elector) Start(ctx context.Context) error {
+	e.mu.Lock()
+	if e.stopped || e.cancel != nil {
+		e.mu.Unlock()
+		return nil
+	}
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+	e.mu.Unlock()
+
+	defer close(e.done)
+
+	e.run(ctx)
+	return nil
+}
+
+func (e *Elector) run(ctx context.Context) {
+	logger := e.logger.With(slog.String("holder", e.lease.holder))
+
+	leading := false
+	var renewed time.Time
+
+	demote := func(reason string) {
+		logger.WarnContext(ctx, "lost scheduler lease, suspending schedulers", slog.String("reason", reason))
+		e.suspend()
+		leading = false
+	}
+
+	timer := e.lease.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if leading {
+				e.suspend()
+			}
+
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := e.lease.Release(releaseCtx); err != nil {
+				logger.ErrorContext(releaseCtx, "failed to release scheduler lease", slog.Any("error", err))
+			}
+			return
+		case <-timer.C():
+			timer.Reset(e.lease.ttl / 3)
+		}
+
+		held, err := e.lease.Acquire(ctx)
+		switch {
+		case err != nil:
+			logger.ErrorContext(ctx, "failed to acquire scheduler lease", slog.Any("error", err))
+			if leading && e.lease.clock.Now().Sub(renewed) >= e.lease.ttl {
+				demote("lease expired")
+			}
+		case held && !leading:
+			logger.InfoContext(ctx, "acquired scheduler lease, starting schedulers")
+			if err := e.start(ctx); err != nil {
+				logger.ErrorContext(ctx, "failed to start schedulers", slog.Any("error", err))
+				e.suspend()
+				continue
+			}
+			leading, renewed = true, e.lease.clock.Now()
+		case held:
+			renewed = e.lease.clock.Now()
+		case leading:
+			demote("lease taken over")
+		}
+	}
+}
+
+func (e *Elector) start(ctx context.Context) error {
+	for _, runner := range e.runners {
+		if err := runner.Start(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Elector) suspend() {
+	for _, runner := range e.runners {
+		runner.Suspend()
+	}
+}
+
+// Stop stops trying to acquire the lease, stops the runners and releases the lease.
+func (e *Elector) Stop() error {
+	e.mu.Lock()
+	e.stopped = true
+	cancel, done := e.cancel, e.done
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	var errs []error
+	for _, runner := range e.runners {
+		if err := runner.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
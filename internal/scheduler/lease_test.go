@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+
+	"github.com/XanderD99/disruptor/internal/models"
+)
+
+const testLeaseTTL = 30 * time.Second
+
+func openTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file:"+filepath.Join(t.TempDir(), "disruptor.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqldb.SetMaxOpenConns(1)
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, model := range []any{(*models.Lease)(nil), (*models.Job)(nil)} {
+		if _, err := db.NewCreateTable().Model(model).Exec(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// instance is one bot process, electing itself with its own clock so a test
+// can stop it from renewing the lease as if it hangs.
+type instance struct {
+	clock      *FakeClock
+	manager    *Manager
+	dispatcher *Dispatcher
+	elector    *Elector
+}
+
+func newInstance(t *testing.T, db *bun.DB, holder string) *instance {
+	t.Helper()
+
+	handler, _ := recorder()
+	manager := newTestManager(t, NewFakeClock(epoch), handler)
+	if err := manager.Schedule(context.Background(), "test", 1, fixed(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := NewDispatcher(db, WithDispatcherClock(NewFakeClock(epoch)), WithDispatcherLogger(slog.New(slog.DiscardHandler)))
+	dispatcher.RegisterHandler(func(context.Context, models.Job) error { return nil })
+
+	clock := NewFakeClock(epoch)
+	lease := NewLease(db, "scheduler", holder, testLeaseTTL, WithLeaseClock(clock))
+
+	return &instance{
+		clock:      clock,
+		manager:    manager,
+		dispatcher: dispatcher,
+		elector:    NewElector(lease, slog.New(slog.DiscardHandler), manager, dispatcher),
+	}
+}
+
+// start runs the elector until the test ends and waits for its first attempt to acquire the lease.
+func (i *instance) start(t *testing.T) {
+	t.Helper()
+
+	go func() { _ = i.elector.Start(context.Background()) }()
+	t.Cleanup(func() { _ = i.elector.Stop() })
+
+	i.clock.BlockUntil(1)
+}
+
+// renew fires the timer of the elector, it tries to acquire the lease again.
+func (i *instance) renew() {
+	i.clock.BlockUntil(1)
+	i.clock.Advance(testLeaseTTL / 3)
+}
+
+// running reports whether the schedulers of the manager and the dispatcher run.
+func (i *instance) running() bool {
+	snapshots := i.manager.Snapshot()
+	if len(snapshots) != 1 || !snapshots[0].Running {
+		return false
+	}
+
+	i.dispatcher.mu.Lock()
+	defer i.dispatcher.mu.Unlock()
+	return i.dispatcher.cancel != nil
+}
+
+// stopped reports whether neither the schedulers of the manager nor the dispatcher run.
+func (i *instance) stopped() bool {
+	for _, snapshot := range i.manager.Snapshot() {
+		if snapshot.Running {
+			return false
+		}
+	}
+
+	i.dispatcher.mu.Lock()
+	defer i.dispatcher.mu.Unlock()
+	return i.dispatcher.cancel == nil
+}
+
+// stays fails the test if cond stops holding within a short while, a standby
+// keeps its runners stopped without any event to wait for.
+func stays(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if !cond() {
+			t.Fatal("condition stopped holding")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestElectorRunsOneInstanceAtATime(t *testing.T) {
+	db := openTestDB(t)
+	a, b := newInstance(t, db, "a"), newInstance(t, db, "b")
+
+	// the first instance acquires the lease and starts its runners
+	a.start(t)
+	eventually(t, a.running)
+
+	// the standby keeps trying but stays stopped while the lease is renewed
+	b.start(t)
+	stays(t, b.stopped)
+
+	a.renew()
+	b.renew()
+	stays(t, b.stopped)
+	if !a.running() {
+		t.Fatal("leader stopped while renewing its lease")
+	}
+
+	// a hangs, b takes over once the lease expired
+	b.clock.BlockUntil(1)
+	b.clock.Advance(testLeaseTTL + time.Second)
+	eventually(t, b.running)
+
+	// a sees the lease taken over and suspends its runners
+	a.renew()
+	eventually(t, a.stopped)
+
+	// once b stops it releases the lease and a starts its suspended runners again
+	if err := b.elector.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, b.stopped)
+
+	a.renew()
+	eventually(t, a.running)
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/disgoorg/snowflake/v2"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/XanderD99/disruptor/pkg/logging"
@@ -15,9 +16,14 @@ import (
 
 func NewManager(opts ...Option[Manager]) *Manager {
 	m := &Manager{
-		schedulers: make(map[string]*Scheduler),
-		builders:   make(map[string]SchedulerBuilder),
-		logger:     slog.Default(),
+		schedulers:  make(map[string]*Scheduler),
+		builders:    make(map[string]SchedulerBuilder),
+		placements:  make(map[placement]string),
+		restored:    make(map[placement]Record),
+		logger:      slog.Default(),
+		clock:       SystemClock(),
+		middlewares: DefaultMiddlewares(),
+		events:      NewBus(),
 	}
 
 	for _, opt := range opts {
@@ -41,14 +47,85 @@ func WithBuilder(key string, builder SchedulerBuilder) Option[Manager] {
 	}
 }
 
+// WithClock sets the clock used by the manager and the schedulers it builds.
+func WithClock(clock Clock) Option[Manager] {
+	return func(m *Manager) {
+		m.clock = clock
+	}
+}
+
+// WithMiddlewares replaces the DefaultMiddlewares applied to the handlers of the
+// schedulers the manager builds.
+func WithMiddlewares(middlewares ...Middleware) Option[Manager] {
+	return func(m *Manager) {
+		m.middlewares = middlewares
+	}
+}
+
+// WithSubscriber subscribes to the events of the schedulers the manager
+// builds and of their handlers.
+func WithSubscriber(subscriber Subscriber) Option[Manager] {
+	return func(m *Manager) {
+		m.events.Subscribe(subscriber)
+	}
+}
+
+// WithStore persists fire times so they survive restarts.
+func WithStore(store Store) Option[Manager] {
+	return func(m *Manager) {
+		m.store = store
+	}
+}
+
+// WithCatchUp sets how fire times missed during downtime are handled. maxDelay
+// is only used by CatchUpDelay.
+func WithCatchUp(policy CatchUp, maxDelay time.Duration) Option[Manager] {
+	return func(m *Manager) {
+		m.catchUp = policy
+		m.catchUpDelay = maxDelay
+	}
+}
+
+// ReconcileFunc returns the guilds that should stay scheduled, guilds missing
+// from it are unscheduled during reconciliation.
+type ReconcileFunc func(ctx context.Context) ([]snowflake.ID, error)
+
+// WithReconciler periodically unschedules guilds that no longer exist and
+// removes the schedulers left without guilds.
+func WithReconciler(every time.Duration, fn ReconcileFunc) Option[Manager] {
+	return func(m *Manager) {
+		m.reconcileEvery = every
+		m.reconcile = fn
+	}
+}
+
 type SchedulerBuilder func(interval time.Duration) *Scheduler
 
+// placement identifies a guild scheduled by a specific builder.
+type placement struct {
+	key     string
+	guildID snowflake.ID
+}
+
 type Manager struct {
 	schedulers map[string]*Scheduler
 	builders   map[string]SchedulerBuilder
+	placements map[placement]string // scheduler key a guild is currently placed in
+	restored   map[placement]Record // persisted fire times of guilds that are not placed yet
 
 	// Dependencies
 	logger *slog.Logger
+	store  Store
+	clock  Clock
+
+	middlewares []Middleware
+	events      *Bus
+
+	catchUp      CatchUp
+	catchUpDelay time.Duration
+
+	reconcile      ReconcileFunc
+	reconcileEvery time.Duration
 
 	mu sync.RWMutex
 
@@ -69,14 +146,41 @@ func (m *Manager) Start(ctx context.Context) error {
 
 	m.logger.InfoContext(m.ctx, "starting voice audio scheduler manager", slog.Int("groups", len(m.schedulers)))
 
+	if err := m.restore(m.ctx); err != nil {
+		m.cancel()
+		m.ctx, m.cancel = nil, nil // not started, so a later Start tries again
+		return err
+	}
+
 	for key, group := range m.schedulers {
 		m.logger.DebugContext(m.ctx, "starting interval group", slog.Group("scheduler", slog.String("key", key)))
 		go group.Start(m.ctx)
 	}
 
+	if m.reconcile != nil && m.reconcileEvery > 0 {
+		go m.reconcileLoop(m.ctx)
+	}
+
 	return nil
 }
 
+// Subscribe subscribes to the events of the schedulers, for components that
+// are created after the manager.
+func (m *Manager) Subscribe(subscriber Subscriber) {
+	m.events.Subscribe(subscriber)
+}
+
+// EventContext returns ctx carrying the event bus of the manager, so Publish
+// reaches its subscribers from outside of the scheduler handlers.
+func (m *Manager) EventContext(ctx context.Context) context.Context {
+	return addClockToContext(addBusToContext(ctx, m.events), m.clock)
+}
+
+// Now returns the time on the clock of the manager.
+func (m *Manager) Now() time.Time {
+	return m.clock.Now()
+}
+
 func (m *Manager) RegisterBuilder(key string, builder SchedulerBuilder) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -118,16 +222,48 @@ func (m *Manager) Stop() error {
 	}
 
 	m.schedulers = make(map[string]*Scheduler)
+	m.placements = make(map[placement]string)
+	m.restored = make(map[placement]Record)
 
 	m.logger.Info("voice audio scheduler manager stopped successfully")
 	return nil
 }
 
+// Suspend stops the schedulers but, unlike Stop, keeps every guild placed so
+// a later Start picks them up again with their persisted fire times.
+func (m *Manager) Suspend() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel == nil {
+		return // not started
+	}
+
+	m.cancel()
+	m.ctx, m.cancel = nil, nil
+
+	for schedKey, group := range m.schedulers {
+		if err := group.Stop(); err != nil {
+			m.logger.Error("failed to stop interval group", slog.Group("scheduler", slog.String("key", schedKey)), slog.Any("error", err))
+		}
+
+		// stopped schedulers can not be started again, move the guilds to a new one
+		fresh, err := m.build(group.key, group.interval)
+		if err != nil {
+			m.logger.Error("failed to rebuild interval group", slog.Group("scheduler", slog.String("key", schedKey)), slog.Any("error", err))
+			continue
+		}
+		group.moveTo(fresh)
+		m.schedulers[schedKey] = fresh
+	}
+
+	m.logger.Info("voice audio scheduler manager suspended", slog.Int("groups", len(m.schedulers)))
+}
+
 func (m *Manager) GetScheduler(scheduler string, interval time.Duration) (*Scheduler, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	key := fmt.Sprintf("%s_%d", scheduler, interval.Milliseconds())
-	if scheduler, exists := m.schedulers[key]; exists {
+	if scheduler, exists := m.schedulers[schedulerKey(scheduler, interval)]; exists {
 		return scheduler, true
 	}
 	return nil, false
@@ -137,19 +273,203 @@ func (m *Manager) AddScheduler(key string, interval time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	schedKey := fmt.Sprintf("%s_%d", key, interval.Milliseconds())
-	if _, exists := m.schedulers[schedKey]; exists {
-		m.logger.Info("scheduler with this interval already exists", slog.String("key", key), slog.Duration("interval", interval))
-		return nil
+	_, err := m.addScheduler(key, interval)
+	return err
+}
+
+// Schedule places a guild in the scheduler for the given key and the interval of
+// its timing. A guild that was scheduled with a different interval is moved out
+// of its previous scheduler, a changed timing within the same scheduler picks a
+// new fire time.
+func (m *Manager) Schedule(ctx context.Context, key string, guildID snowflake.ID, timing Timing) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := placement{key: key, guildID: guildID}
+	schedKey := schedulerKey(key, timing.Interval())
+
+	var last time.Time
+	if current, ok := m.placements[p]; ok {
+		if group, exists := m.schedulers[current]; exists {
+			if existing, ok := group.Timing(guildID); ok && current == schedKey && existing.String() == timing.String() {
+				return nil
+			}
+			if r, ok := group.record(guildID); ok {
+				last = r.Last
+			}
+			group.Remove(guildID)
+			if current != schedKey {
+				m.release(current)
+			}
+		}
+		delete(m.placements, p)
 	}
 
-	builder, ok := m.builders[key]
+	group, err := m.addScheduler(key, timing.Interval())
+	if err != nil {
+		return err
+	}
+
+	now := m.clock.Now()
+	next, deferred := timing.Next(now), time.Time{}
+	if r, ok := m.restored[p]; ok {
+		next, deferred = catchUp(r, timing, m.catchUp, m.catchUpDelay, now)
+		last = r.Last
+		delete(m.restored, p)
+	}
+
+	group.restore(guildID, timing, next, last, deferred)
+	m.placements[p] = schedKey
+	m.persist(ctx, group, guildID)
+
+	m.logger.Debug("guild scheduled", slog.String("key", key), slog.String("guild.id", guildID.String()), slog.String("timing", timing.String()), slog.Time("next", next))
+	return nil
+}
+
+// Unschedule removes a guild from the scheduler it was placed in for the given key.
+func (m *Manager) Unschedule(ctx context.Context, key string, guildID snowflake.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := placement{key: key, guildID: guildID}
+	delete(m.restored, p)
+
+	m.forget(ctx, key, guildID)
+
+	current, ok := m.placements[p]
 	if !ok {
-		m.logger.Error("no builder registered for key", slog.String("key", key))
-		return fmt.Errorf("no builder registered for key %s", key)
+		return
 	}
 
-	group := builder(interval)
+	if group, exists := m.schedulers[current]; exists {
+		group.Remove(guildID)
+		m.release(current)
+	}
+	delete(m.placements, p)
+
+	m.logger.Debug("guild unscheduled", slog.String("key", key), slog.String("guild.id", guildID.String()))
+}
+
+// RemoveScheduler stops the scheduler for key and interval and unschedules
+// the guilds placed in it.
+func (m *Manager) RemoveScheduler(key string, interval time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	schedKey := schedulerKey(key, interval)
+	if _, exists := m.schedulers[schedKey]; !exists {
+		return fmt.Errorf("no scheduler found for key %s and interval %s", key, interval)
+	}
+
+	for p, current := range m.placements {
+		if current == schedKey {
+			delete(m.placements, p)
+		}
+	}
+
+	return m.removeScheduler(schedKey)
+}
+
+// Reconcile unschedules guilds that are not in guildIDs and removes the
+// schedulers that are left without guilds.
+func (m *Manager) Reconcile(ctx context.Context, guildIDs []snowflake.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keep := make(map[snowflake.ID]struct{}, len(guildIDs))
+	for _, guildID := range guildIDs {
+		keep[guildID] = struct{}{}
+	}
+
+	removed := 0
+	for p, current := range m.placements {
+		if _, ok := keep[p.guildID]; ok {
+			continue
+		}
+
+		if group, exists := m.schedulers[current]; exists {
+			group.Remove(p.guildID)
+		}
+		delete(m.placements, p)
+		removed++
+
+		m.forget(ctx, p.key, p.guildID)
+	}
+
+	for schedKey := range m.schedulers {
+		m.release(schedKey)
+	}
+
+	m.logger.DebugContext(ctx, "reconciled schedulers", slog.Int("unscheduled", removed), slog.Int("groups", len(m.schedulers)))
+}
+
+func (m *Manager) reconcileLoop(ctx context.Context) {
+	timer := m.clock.NewTimer(m.reconcileEvery)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			timer.Reset(m.reconcileEvery)
+
+			guildIDs, err := m.reconcile(ctx)
+			if err != nil {
+				m.logger.ErrorContext(ctx, "failed to reconcile schedulers", slog.Any("error", err))
+				continue
+			}
+			m.Reconcile(ctx, guildIDs)
+		}
+	}
+}
+
+// NextFire returns the next fire time of a guild for the given key.
+func (m *Manager) NextFire(key string, guildID snowflake.ID) (time.Time, bool) {
+	group, ok := m.placed(key, guildID)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return group.Next(guildID)
+}
+
+// Timing returns the timing a guild is scheduled with for the given key.
+func (m *Manager) Timing(key string, guildID snowflake.ID) (Timing, bool) {
+	group, ok := m.placed(key, guildID)
+	if !ok {
+		return nil, false
+	}
+
+	return group.Timing(guildID)
+}
+
+// placed returns the scheduler a guild is placed in for the given key.
+func (m *Manager) placed(key string, guildID snowflake.ID) (*Scheduler, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	current, ok := m.placements[placement{key: key, guildID: guildID}]
+	if !ok {
+		return nil, false
+	}
+
+	group, exists := m.schedulers[current]
+	return group, exists
+}
+
+// addScheduler returns the scheduler for key and interval, building and starting
+// it when it does not exist yet. Must be called with the lock held.
+func (m *Manager) addScheduler(key string, interval time.Duration) (*Scheduler, error) {
+	schedKey := schedulerKey(key, interval)
+	if group, exists := m.schedulers[schedKey]; exists {
+		return group, nil
+	}
+
+	group, err := m.build(key, interval)
+	if err != nil {
+		return nil, err
+	}
 	m.schedulers[schedKey] = group
 
 	m.logger.Info("new interval group added", slog.String("key", key), slog.Duration("interval", interval))
@@ -157,5 +477,122 @@ func (m *Manager) AddScheduler(key string, interval time.Duration) error {
 		go group.Start(m.ctx)
 	}
 
+	return group, nil
+}
+
+// build creates a scheduler with the builder registered for key. Must be called with the lock held.
+func (m *Manager) build(key string, interval time.Duration) (*Scheduler, error) {
+	builder, ok := m.builders[key]
+	if !ok {
+		m.logger.Error("no builder registered for key", slog.String("key", key))
+		return nil, fmt.Errorf("no builder registered for key %s", key)
+	}
+
+	group := builder(interval)
+	group.Use(m.middlewares...)
+	group.key = key
+	group.store = m.store
+	group.clock = m.clock
+	group.events = m.events
+
+	return group, nil
+}
+
+// release removes a scheduler once no guilds are placed in it anymore. Must be
+// called with the lock held.
+func (m *Manager) release(schedKey string) {
+	group, exists := m.schedulers[schedKey]
+	if !exists || group.Len() > 0 {
+		return
+	}
+
+	if err := m.removeScheduler(schedKey); err != nil {
+		m.logger.Error("failed to remove unused interval group", slog.Group("scheduler", slog.String("key", schedKey)), slog.Any("error", err))
+	}
+}
+
+// removeScheduler stops and forgets a scheduler. Must be called with the lock held.
+func (m *Manager) removeScheduler(schedKey string) error {
+	group, exists := m.schedulers[schedKey]
+	if !exists {
+		return nil
+	}
+
+	delete(m.schedulers, schedKey)
+	if err := group.Stop(); err != nil {
+		return fmt.Errorf("failed to stop interval group %v: %w", schedKey, err)
+	}
+
+	m.logger.Info("interval group removed", slog.Group("scheduler", slog.String("key", schedKey)))
 	return nil
 }
+
+// restore loads persisted fire times. Guilds that are already placed are updated
+// right away, the others are applied once they get scheduled. Must be called with the lock held.
+func (m *Manager) restore(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	records, err := m.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore fire times: %w", err)
+	}
+
+	now := m.clock.Now()
+	for _, r := range records {
+		p := placement{key: r.Key, guildID: r.GuildID}
+
+		current, ok := m.placements[p]
+		if !ok {
+			m.restored[p] = r
+			continue
+		}
+
+		if group, exists := m.schedulers[current]; exists {
+			if timing, ok := group.Timing(r.GuildID); ok {
+				next, deferred := catchUp(r, timing, m.catchUp, m.catchUpDelay, now)
+				group.restore(r.GuildID, timing, next, r.Last, deferred)
+				m.persist(ctx, group, r.GuildID)
+			}
+		}
+	}
+
+	m.logger.InfoContext(ctx, "restored fire times", slog.Int("count", len(records)), slog.String("catch_up", m.catchUp.String()))
+	return nil
+}
+
+// persist saves the fire times of a guild. Must be called with the lock held.
+// A manager that is not started does not persist, so a standby instance does
+// not overwrite the fire times of the running one.
+func (m *Manager) persist(ctx context.Context, group *Scheduler, guildID snowflake.ID) {
+	if m.store == nil || m.ctx == nil {
+		return
+	}
+
+	r, ok := group.record(guildID)
+	if !ok {
+		return
+	}
+
+	if err := m.store.Save(ctx, r); err != nil {
+		m.logger.ErrorContext(ctx, "failed to persist fire time", slog.String("guild.id", guildID.String()), slog.Any("error", err))
+	}
+}
+
+// forget deletes the persisted fire times of a guild. Must be called with the
+// lock held. Like persist, a manager that is not started does not delete, so a
+// standby instance does not wipe the fire times of the running one.
+func (m *Manager) forget(ctx context.Context, key string, guildID snowflake.ID) {
+	if m.store == nil || m.ctx == nil {
+		return
+	}
+
+	if err := m.store.Delete(ctx, key, guildID); err != nil {
+		m.logger.ErrorContext(ctx, "failed to delete persisted fire time", slog.String("guild.id", guildID.String()), slog.Any("error", err))
+	}
+}
+
+func schedulerKey(key string, interval time.Duration) string {
+	return fmt.Sprintf("%s_%d", key, interval.Milliseconds())
+}
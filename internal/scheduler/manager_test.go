@@ -0,0 +1,388 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// memoryStore is a Store that keeps its records in memory.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[placement]Record
+}
+
+func newMemoryStore(records ...Record) *memoryStore {
+	s := &memoryStore{records: make(map[placement]Record)}
+	for _, r := range records {
+		s.records[placement{key: r.Key, guildID: r.GuildID}] = r
+	}
+	return s
+}
+
+func (s *memoryStore) Load(context.Context) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, records ...Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range records {
+		s.records[placement{key: r.Key, guildID: r.GuildID}] = r
+	}
+	return nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string, guildID snowflake.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, placement{key: key, guildID: guildID})
+	return nil
+}
+
+func (s *memoryStore) get(key string, guildID snowflake.ID) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[placement{key: key, guildID: guildID}]
+	return r, ok
+}
+
+func newTestManager(t *testing.T, clock *FakeClock, handler HandleFunc, opts ...Option[Manager]) *Manager {
+	t.Helper()
+
+	opts = append([]Option[Manager]{
+		WithClock(clock),
+		WithMiddlewares(),
+		WithBuilder("test", func(interval time.Duration) *Scheduler {
+			return NewScheduler(interval, handler)
+		}),
+	}, opts...)
+
+	m := NewManager(opts...)
+	t.Cleanup(func() { _ = m.Stop() })
+	return m
+}
+
+func TestManagerGroupsGuildsByInterval(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks := recorder()
+	m := newTestManager(t, clock, handler)
+
+	ctx := context.Background()
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for guildID, timing := range map[snowflake.ID]Timing{1: fixed(time.Minute), 2: fixed(time.Minute), 3: fixed(time.Hour)} {
+		if err := m.Schedule(ctx, "test", guildID, timing); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots := m.Snapshot()
+	if len(snapshots) != 2 || snapshots[0].Guilds != 2 || snapshots[1].Guilds != 1 {
+		t.Fatalf("snapshots are %+v, want a scheduler with 2 guilds and one with 1", snapshots)
+	}
+
+	next, ok := m.NextFire("test", 1)
+	if !ok || !next.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("next fire of guild 1 is %s, want %s", next, epoch.Add(time.Minute))
+	}
+
+	clock.BlockUntil(2)
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1, 2}) {
+		t.Fatalf("tick handled %v, want [1 2]", got)
+	}
+
+	// moving the last guild out of a scheduler removes the scheduler
+	if err := m.Schedule(ctx, "test", 3, fixed(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if snapshots := m.Snapshot(); len(snapshots) != 1 || snapshots[0].Guilds != 3 {
+		t.Fatalf("snapshots are %+v, want one scheduler with 3 guilds", snapshots)
+	}
+
+	for _, guildID := range []snowflake.ID{1, 2, 3} {
+		m.Unschedule(ctx, "test", guildID)
+	}
+	if snapshots := m.Snapshot(); len(snapshots) != 0 {
+		t.Fatalf("snapshots are %+v, want no schedulers", snapshots)
+	}
+}
+
+func TestManagerRestoresPersistedFireTimes(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks := recorder()
+
+	store := newMemoryStore(
+		Record{Key: "test", GuildID: 1, Next: epoch.Add(30 * time.Second)},
+		Record{Key: "test", GuildID: 2, Next: epoch.Add(-time.Hour)}, // missed while offline
+	)
+	m := newTestManager(t, clock, handler, WithStore(store), WithCatchUp(CatchUpSkip, 0))
+
+	ctx := context.Background()
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, guildID := range []snowflake.ID{1, 2} {
+		if err := m.Schedule(ctx, "test", guildID, fixed(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if next, _ := m.NextFire("test", 1); !next.Equal(epoch.Add(30 * time.Second)) {
+		t.Fatalf("next fire of guild 1 is %s, want the persisted %s", next, epoch.Add(30*time.Second))
+	}
+	if next, _ := m.NextFire("test", 2); !next.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("next fire of guild 2 is %s, want the skipped %s", next, epoch.Add(time.Minute))
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1}) {
+		t.Fatalf("tick handled %v, want [1]", got)
+	}
+
+	eventually(t, func() bool {
+		r, ok := store.get("test", 1)
+		return ok && r.Last.Equal(epoch.Add(30*time.Second)) && r.Next.Equal(epoch.Add(90*time.Second))
+	})
+}
+
+func TestManagerSuspendKeepsGuildsPlaced(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks := recorder()
+	store := newMemoryStore()
+	m := newTestManager(t, clock, handler, WithStore(store))
+
+	ctx := context.Background()
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Schedule(ctx, "test", 1, fixed(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+
+	m.Suspend()
+	eventually(t, func() bool { return clock.Timers() == 0 })
+
+	clock.Advance(time.Minute)
+	noTick(t, ticks)
+
+	if snapshots := m.Snapshot(); len(snapshots) != 1 || snapshots[0].Guilds != 1 || snapshots[0].Running {
+		t.Fatalf("snapshots are %+v, want one stopped scheduler with the guild", snapshots)
+	}
+
+	// the fire time missed while suspended fires once the manager starts again
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1}) {
+		t.Fatalf("tick handled %v, want [1]", got)
+	}
+}
+
+func TestManagerStopStopsSchedulers(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks := recorder()
+	m := newTestManager(t, clock, handler)
+
+	ctx := context.Background()
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Schedule(ctx, "test", 1, fixed(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+
+	if err := m.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool { return clock.Timers() == 0 })
+
+	clock.Advance(time.Hour)
+	noTick(t, ticks)
+
+	if _, ok := m.NextFire("test", 1); ok {
+		t.Fatal("guild is still placed after Stop")
+	}
+}
+
+func TestManagerRestoresStaggeredGuilds(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks := recorder()
+
+	// fired at epoch-10s but was staggered to epoch+20s before the restart
+	store := newMemoryStore(Record{Key: "test", GuildID: 1, Next: epoch.Add(20 * time.Second), Deferred: epoch.Add(-10 * time.Second)})
+	m := newTestManager(t, clock, handler, WithStore(store))
+
+	ctx := context.Background()
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Schedule(ctx, "test", 1, fixed(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(20 * time.Second)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1}) {
+		t.Fatalf("tick handled %v, want [1]", got)
+	}
+
+	// the following fire keeps to the schedule of the original fire time
+	eventually(t, func() bool {
+		r, ok := store.get("test", 1)
+		return ok && r.Next.Equal(epoch.Add(50*time.Second)) && r.Deferred.IsZero()
+	})
+}
+
+func TestManagerEventsFollowTheClock(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	ticks := make(chan TickStarted, 1)
+	handled := make(chan time.Time, 1)
+	m := newTestManager(t, clock, func(ctx context.Context) error {
+		handled <- Now(ctx)
+		return nil
+	}, WithSubscriber(func(_ context.Context, event Event) {
+		if tick, ok := event.(TickStarted); ok {
+			ticks <- tick
+		}
+	}))
+
+	ctx := context.Background()
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Schedule(ctx, "test", 1, fixed(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	tick := <-ticks
+	if !tick.At.Equal(epoch.Add(time.Minute)) || !slices.Equal(tick.Guilds, []snowflake.ID{1}) {
+		t.Fatalf("tick is %+v, want guild 1 at %s", tick, epoch.Add(time.Minute))
+	}
+	if now := <-handled; !now.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("handler saw %s, want the time of the clock %s", now, epoch.Add(time.Minute))
+	}
+	if now := Now(m.EventContext(ctx)); !now.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("event context saw %s, want the time of the clock %s", now, epoch.Add(time.Minute))
+	}
+	if now := m.Now(); !now.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("manager saw %s, want the time of the clock %s", now, epoch.Add(time.Minute))
+	}
+}
+
+func TestManagerRestoresJitteredTimings(t *testing.T) {
+	timings := []Timing{Every(time.Minute), Uniform(30*time.Second, 90*time.Second), Exponential(time.Minute)}
+
+	for _, timing := range timings {
+		for range 50 {
+			clock := NewFakeClock(epoch)
+			handler, _ := recorder()
+
+			store := newMemoryStore(
+				Record{Key: "test", GuildID: 1, Next: epoch.Add(110 * time.Second)}, // within twice the interval
+				Record{Key: "test", GuildID: 2, Next: epoch.Add(time.Hour)},         // settings changed while offline
+			)
+			m := newTestManager(t, clock, handler, WithStore(store))
+
+			ctx := context.Background()
+			if err := m.Start(ctx); err != nil {
+				t.Fatal(err)
+			}
+			for _, guildID := range []snowflake.ID{1, 2} {
+				if err := m.Schedule(ctx, "test", guildID, timing); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if next, _ := m.NextFire("test", 1); !next.Equal(epoch.Add(110 * time.Second)) {
+				t.Fatalf("%s: next fire of guild 1 is %s, want the persisted %s", timing, next, epoch.Add(110*time.Second))
+			}
+			if next, _ := m.NextFire("test", 2); !next.Before(epoch.Add(time.Hour)) {
+				t.Fatalf("%s: next fire of guild 2 is the persisted %s, want a new one", timing, next)
+			}
+			_ = m.Stop()
+		}
+	}
+}
+
+func TestManagerStandbyKeepsPersistedFireTimes(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, _ := recorder()
+
+	store := newMemoryStore(
+		Record{Key: "test", GuildID: 1, Next: epoch.Add(30 * time.Second)},
+		Record{Key: "test", GuildID: 2, Next: epoch.Add(30 * time.Second)},
+	)
+	m := newTestManager(t, clock, handler, WithStore(store))
+
+	// a manager that is not started is a standby, the running one owns the store
+	ctx := context.Background()
+	for _, guildID := range []snowflake.ID{1, 2} {
+		if err := m.Schedule(ctx, "test", guildID, fixed(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Unschedule(ctx, "test", 1)
+	m.Reconcile(ctx, nil)
+
+	for _, guildID := range []snowflake.ID{1, 2} {
+		if _, ok := store.get("test", guildID); !ok {
+			t.Fatalf("standby deleted the fire time of guild %s", guildID)
+		}
+	}
+}
+
+// failingStore is a Store that fails to load.
+type failingStore struct{ *memoryStore }
+
+func (failingStore) Load(context.Context) ([]Record, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestManagerFailedStartIsNotStarted(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, _ := recorder()
+
+	store := failingStore{newMemoryStore()}
+	m := newTestManager(t, clock, handler, WithStore(store))
+
+	ctx := context.Background()
+	if err := m.Start(ctx); err == nil {
+		t.Fatal("start succeeded, want the error of the store")
+	}
+	if err := m.Schedule(ctx, "test", 1, fixed(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.get("test", 1); ok {
+		t.Fatal("manager persisted a fire time after a failed start")
+	}
+	if snapshots := m.Snapshot(); len(snapshots) != 1 || snapshots[0].Running {
+		t.Fatalf("snapshots are %+v, want one stopped scheduler", snapshots)
+	}
+}
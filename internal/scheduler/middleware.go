@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/XanderD99/disruptor/internal/util"
+	"github.com/XanderD99/disruptor/pkg/logging"
+)
+
+const instrumentationName = "github.com/XanderD99/disruptor/internal/scheduler"
+
+// ErrHandlerPanic is returned by Recover when the handler panicked.
+var ErrHandlerPanic = errors.New("scheduler handler panicked")
+
+// Middleware wraps a HandleFunc, like handler.Middleware does for commands.
+type Middleware func(next HandleFunc) HandleFunc
+
+// Chain wraps handler in middlewares, the first middleware is the outermost.
+func Chain(handler HandleFunc, middlewares ...Middleware) HandleFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// DefaultMiddlewares are applied to the handlers of schedulers built by a
+// Manager. Recover is the innermost, so the others see a panic as an error.
+func DefaultMiddlewares() []Middleware {
+	return []Middleware{Tracing, Logger, Metrics(), Recover}
+}
+
+// Recover turns a panic in the handler into an error, so it does not crash the bot.
+func Recover(next HandleFunc) HandleFunc {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "Recovered from panic in scheduler handler", slog.Any("panic", r), slog.String("stack", string(debug.Stack())))
+				err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+			}
+		}()
+
+		return next(ctx)
+	}
+}
+
+// Logger adds the scheduler and the due guilds to the logger in the context
+// and logs when a tick starts and finishes.
+func Logger(next HandleFunc) HandleFunc {
+	return func(ctx context.Context) error {
+		key, _ := util.GetSchedulerKeyFromContext(ctx)
+		guildIDs, _ := util.GetGuildIDsFromContext(ctx)
+
+		logger := logging.FromContext(ctx).With(slog.Group("scheduler", slog.String("key", key), slog.Int("guilds", len(guildIDs))))
+		ctx = logging.AddToContext(ctx, logger)
+
+		logger.DebugContext(ctx, "handling tick")
+		started := time.Now()
+		err := next(ctx)
+		logger.DebugContext(ctx, "tick handled", slog.Duration("duration", time.Since(started)), slog.Bool("failed", err != nil))
+
+		return err
+	}
+}
+
+// Tracing wraps every tick in a span.
+func Tracing(next HandleFunc) HandleFunc {
+	tracer := otel.Tracer(instrumentationName)
+
+	return func(ctx context.Context) error {
+		key, _ := util.GetSchedulerKeyFromContext(ctx)
+		interval, _ := util.GetIntervalFromContext(ctx)
+		guildIDs, _ := util.GetGuildIDsFromContext(ctx)
+
+		ctx, span := tracer.Start(ctx, "scheduler.tick",
+			trace.WithAttributes(
+				attribute.String("scheduler.key", key),
+				attribute.String("scheduler.interval", interval.String()),
+				attribute.Int("scheduler.guilds", len(guildIDs)),
+			),
+		)
+		defer span.End()
+
+		err := next(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
+
+// Timeout cancels the context of the handler once it runs longer than timeout
+// on clock, the returned error wraps ErrHandlerTimeout when the handler failed
+// after running out of time. Schedulers apply it, see WithHandlerTimeout.
+func Timeout(timeout time.Duration, clock Clock) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context) error {
+			ctx, cancel := context.WithCancelCause(ctx)
+			defer cancel(nil)
+
+			timer := clock.AfterFunc(timeout, func() { cancel(ErrHandlerTimeout) })
+			defer timer.Stop()
+
+			err := next(ctx)
+			if err != nil && errors.Is(context.Cause(ctx), ErrHandlerTimeout) && !errors.Is(err, ErrHandlerTimeout) {
+				return fmt.Errorf("%w: %w", ErrHandlerTimeout, err)
+			}
+			return err
+		}
+	}
+}
+
+// Metrics counts ticks and records their duration.
+func Metrics() Middleware {
+	meter := otel.Meter(instrumentationName)
+
+	ticks, _ := meter.Int64Counter("scheduler.ticks", metric.WithDescription("Scheduler ticks by result"))
+	guilds, _ := meter.Int64Counter("scheduler.guilds", metric.WithDescription("Guilds passed to scheduler handlers"))
+	duration, _ := meter.Float64Histogram("scheduler.tick.duration", metric.WithDescription("Duration of scheduler handlers"), metric.WithUnit("s"))
+
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context) error {
+			key, _ := util.GetSchedulerKeyFromContext(ctx)
+			guildIDs, _ := util.GetGuildIDsFromContext(ctx)
+
+			started := time.Now()
+			err := next(ctx)
+
+			result := "ok"
+			switch {
+			case errors.Is(err, ErrHandlerPanic):
+				result = "panic"
+			case errors.Is(err, ErrHandlerTimeout) || errors.Is(context.Cause(ctx), ErrHandlerTimeout):
+				result = "timeout"
+			case err != nil:
+				result = "error"
+			}
+
+			attrs := metric.WithAttributes(attribute.String("scheduler.key", key), attribute.String("result", result))
+			ticks.Add(ctx, 1, attrs)
+			guilds.Add(ctx, int64(len(guildIDs)), attrs)
+			duration.Record(ctx, time.Since(started).Seconds(), attrs)
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeoutCancelsSlowHandlers(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	handler := Timeout(time.Minute, clock)(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	result := make(chan error, 1)
+	go func() { result <- handler(context.Background()) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	err := <-result
+	if !errors.Is(err, ErrHandlerTimeout) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("handler returned %v, want it to wrap ErrHandlerTimeout and the error of the handler", err)
+	}
+}
+
+func TestTimeoutStopsItsTimer(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	handler := Timeout(time.Minute, clock)(func(context.Context) error { return nil })
+	if err := handler(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if timers := clock.Timers(); timers != 0 {
+		t.Fatalf("%d timers armed after the handler returned, want 0", timers)
+	}
+}
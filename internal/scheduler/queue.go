@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// entry is a single guild tracked by a Scheduler.
+type entry struct {
+	guildID snowflake.ID
+	timing  Timing
+	next    time.Time
+	last    time.Time
+	// deferred is the fire time a staggered guild was pushed back from, zero when it is not staggered
+	deferred time.Time
+	index    int // position in the queue, maintained by container/heap
+}
+
+// record returns the persistable state of the entry.
+func (e *entry) record(key string) Record {
+	return Record{Key: key, GuildID: e.guildID, Next: e.next, Last: e.last, Deferred: e.deferred}
+}
+
+// queue is a min-heap of entries ordered by their next fire time.
+type queue []*entry
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *queue) Push(x any) {
+	e := x.(*entry) //nolint:errcheck
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *queue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*q = old[:n-1]
+	return e
+}
+
+// peek returns the entry with the earliest fire time without removing it.
+func (q queue) peek() *entry {
+	if len(q) == 0 {
+		return nil
+	}
+	return q[0]
+}
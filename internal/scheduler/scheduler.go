@@ -1,17 +1,29 @@
 package scheduler
 
 import (
+	"container/heap"
 	"context"
+	"errors"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/disgoorg/snowflake/v2"
+
 	"github.com/XanderD99/disruptor/internal/util"
 	"github.com/XanderD99/disruptor/pkg/logging"
 )
 
 type HandleFunc func(ctx context.Context) error
 
+// ErrHandlerTimeout is the cause of the handler context being cancelled when
+// the handler runs longer than its timeout.
+var ErrHandlerTimeout = errors.New("scheduler handler timed out")
+
+// Scheduler runs a handler for the guilds sharing an interval. Every guild has
+// its own Timing and fire time, so guilds in the same scheduler are not
+// disrupted at the same instant.
 type Scheduler struct {
 	handler HandleFunc
 
@@ -20,37 +32,270 @@ type Scheduler struct {
 
 	stopCh   chan struct{}
 	stopOnce sync.Once // Ensures stopCh is closed only once
+	wakeCh   chan struct{}
+
+	clock    Clock
+	timer    Timer // created by Start, so the Manager can still replace the clock
+	interval time.Duration
+
+	// Persistence and events, set by the Manager
+	key    string
+	store  Store
+	events *Bus
+
+	queue   queue
+	entries map[snowflake.ID]*entry
+
+	// Handler runs
+	timeout  time.Duration
+	overlap  Overlap
+	stagger  float64                   // share of the time until their next fire that due guilds are spread over, 0 fires them at once
+	inflight int                       // running handlers
+	busy     map[snowflake.ID]int      // guilds being handled, by the amount of handlers handling them
+	pending  map[snowflake.ID]struct{} // guilds queued behind their running tick
+	wg       sync.WaitGroup
 
-	timer        *time.Timer
-	interval     time.Duration
-	nextInterval time.Time
+	// Statistics of the last runs, see Snapshot
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastErr      error
+	runs         int
+	failures     int
+	skipped      int
+	timedOut     int
 }
 
-func NewScheduler(interval time.Duration, handler HandleFunc) *Scheduler {
+func NewScheduler(interval time.Duration, handler HandleFunc, opts ...Option[Scheduler]) *Scheduler {
 	g := &Scheduler{
-		timer:        time.NewTimer(interval),
-		interval:     interval,
-		nextInterval: time.Now().Add(interval),
-		handler:      handler,
-		stopCh:       make(chan struct{}),
+		clock:    SystemClock(),
+		interval: interval,
+		handler:  handler,
+		stopCh:   make(chan struct{}),
+		wakeCh:   make(chan struct{}, 1),
+		entries:  make(map[snowflake.ID]*entry),
+		busy:     make(map[snowflake.ID]int),
+		pending:  make(map[snowflake.ID]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(g)
 	}
 
 	return g
 }
 
+// Use wraps the handler of the scheduler in middlewares, the first middleware
+// is the outermost. It must be called before the scheduler is started.
+func (t *Scheduler) Use(middlewares ...Middleware) {
+	t.handler = Chain(t.handler, middlewares...)
+}
+
+// WithHandlerTimeout wraps the handler in the Timeout middleware on the clock
+// of the scheduler, 0 disables the timeout.
+func WithHandlerTimeout(timeout time.Duration) Option[Scheduler] {
+	return func(s *Scheduler) {
+		s.timeout = timeout
+	}
+}
+
+// WithOverlap sets what happens to guilds that are due while their previous tick
+// is still being handled.
+func WithOverlap(overlap Overlap) Option[Scheduler] {
+	return func(s *Scheduler) {
+		s.overlap = overlap
+	}
+}
+
+// WithDispatch sets how the guilds that are due at the same time are
+// disrupted. DispatchStagger spreads them over the first share window of the
+// time until their next fire, window is clamped to 0-1.
+func WithDispatch(dispatch Dispatch, window float64) Option[Scheduler] {
+	return func(s *Scheduler) {
+		s.stagger = 0
+		if dispatch == DispatchStagger {
+			s.stagger = min(max(window, 0), 1)
+		}
+	}
+}
+
+// WithSchedulerClock sets the clock of a scheduler that is not built by a
+// Manager, schedulers built by a Manager use the clock of the Manager.
+func WithSchedulerClock(clock Clock) Option[Scheduler] {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
 func (t *Scheduler) Interval() time.Duration {
 	return t.interval
 }
 
-// NextInterval returns the next scheduled interval time.
+// NextInterval returns the earliest fire time of all guilds in the scheduler,
+// or the zero time when no guilds are scheduled.
 func (t *Scheduler) NextInterval() time.Time {
-	return t.nextInterval
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if e := t.queue.peek(); e != nil {
+		return e.next
+	}
+	return time.Time{}
+}
+
+// Next returns the next fire time of the given guild.
+func (t *Scheduler) Next(guildID snowflake.ID) (time.Time, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	e, ok := t.entries[guildID]
+	if !ok {
+		return time.Time{}, false
+	}
+	return e.next, true
+}
+
+// Len returns the amount of guilds in the scheduler.
+func (t *Scheduler) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.entries)
+}
+
+// Add schedules a guild and returns its next fire time. A nil timing fires once
+// per interval of the scheduler. Adding a guild that is already scheduled with
+// the same timing keeps its current fire time.
+func (t *Scheduler) Add(guildID snowflake.ID, timing Timing) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if timing == nil {
+		timing = Every(t.interval)
+	}
+
+	if e, ok := t.entries[guildID]; ok && e.timing.String() == timing.String() {
+		return e.next
+	}
+
+	return t.set(guildID, timing, timing.Next(t.clock.Now()), time.Time{}, time.Time{})
+}
+
+// Timing returns the timing of the given guild.
+func (t *Scheduler) Timing(guildID snowflake.ID) (Timing, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	e, ok := t.entries[guildID]
+	if !ok {
+		return nil, false
+	}
+	return e.timing, true
+}
+
+// restore schedules a guild with a known next and last fire time, replacing
+// the entry if the guild is already scheduled. deferred is the fire time a
+// staggered guild was pushed back from, zero when it is not staggered.
+func (t *Scheduler) restore(guildID snowflake.ID, timing Timing, next, last, deferred time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.set(guildID, timing, next, last, deferred)
 }
 
-// Reset resets the timer and updates the next interval time.
+// set must be called with the lock held.
+func (t *Scheduler) set(guildID snowflake.ID, timing Timing, next, last, deferred time.Time) time.Time {
+	if e, ok := t.entries[guildID]; ok {
+		e.timing, e.next, e.last, e.deferred = timing, next, last, deferred
+		heap.Fix(&t.queue, e.index)
+	} else {
+		e := &entry{guildID: guildID, timing: timing, next: next, last: last, deferred: deferred}
+		t.entries[guildID] = e
+		heap.Push(&t.queue, e)
+	}
+	t.wake()
+
+	return next
+}
+
+// moveTo schedules every guild of the scheduler in other, keeping their fire times.
+func (t *Scheduler) moveTo(other *Scheduler) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, e := range t.entries {
+		other.restore(e.guildID, e.timing, e.next, e.last, e.deferred)
+	}
+}
+
+// record returns the persistable state of a guild.
+func (t *Scheduler) record(guildID snowflake.ID) (Record, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	e, ok := t.entries[guildID]
+	if !ok {
+		return Record{}, false
+	}
+	return e.record(t.key), true
+}
+
+// catchUp decides the next fire time of a restored guild whose fire time may
+// have passed while the bot was offline. The fire time a staggered guild was
+// pushed back from is kept as long as its persisted fire time is.
+func catchUp(r Record, timing Timing, policy CatchUp, maxDelay time.Duration, now time.Time) (next, deferred time.Time) {
+	if r.Next.After(now) {
+		// a fixed bound rather than a drawn delay, so jittered timings restore the same way every time
+		if bound := 2 * timing.Interval(); bound > 0 && r.Next.Sub(now) > bound {
+			return timing.Next(now), time.Time{} // settings changed while offline
+		}
+		return r.Next, r.Deferred
+	}
+
+	switch policy {
+	case CatchUpFire:
+		return now, time.Time{}
+	case CatchUpSkip:
+		return timing.Next(now), time.Time{}
+	default:
+		if maxDelay <= 0 {
+			return now, time.Time{}
+		}
+		return now.Add(time.Duration(util.RandomInt(0, int(maxDelay/time.Millisecond))) * time.Millisecond), time.Time{}
+	}
+}
+
+// Remove unschedules a guild, it returns false if the guild was not scheduled.
+func (t *Scheduler) Remove(guildID snowflake.ID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[guildID]
+	if !ok {
+		return false
+	}
+
+	heap.Remove(&t.queue, e.index)
+	delete(t.entries, guildID)
+	t.wake()
+
+	return true
+}
+
+// Reset re-arms the timer for the earliest scheduled guild.
 func (t *Scheduler) Reset() {
-	t.nextInterval = time.Now().Add(t.interval)
-	t.timer.Reset(t.interval)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.timer == nil {
+		return // not started yet
+	}
+
+	e := t.queue.peek()
+	if e == nil {
+		t.timer.Stop()
+		return
+	}
+	t.timer.Reset(max(e.next.Sub(t.clock.Now()), 0))
 }
 
 // GetInterval returns the current interval duration.
@@ -66,8 +311,10 @@ func (ig *Scheduler) IsRunning() bool {
 	return ig.running
 }
 
-// Start begins the periodic execution loop.
-// Moves timer reset after interval handling and simplifies shutdown logic.
+// Start begins the execution loop. Each time the timer fires, all guilds that
+// are due are passed to the handler and rescheduled following their timing. The
+// handler runs next to the loop, so a slow handler does not delay the timer;
+// guilds that are due while their previous tick runs follow the overlap policy.
 func (ig *Scheduler) Start(ctx context.Context) {
 	ig.mu.Lock()
 	if ig.running {
@@ -75,26 +322,41 @@ func (ig *Scheduler) Start(ctx context.Context) {
 		return
 	}
 	ig.running = true
+	ig.timer = ig.clock.NewTimer(ig.interval)
+	ig.timer.Stop() // armed by Reset once a guild is scheduled
+	timer := ig.timer
 	ig.mu.Unlock()
 
-	defer ig.timer.Stop()
+	defer timer.Stop()
 
-	go func() {
-		<-ig.stopCh
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		ig.wg.Wait() // running handlers see the cancelled context
 	}()
 
 	logger := logging.FromContext(ctx)
 
+	ig.Reset()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ig.timer.C:
-			// add interval to context
-			ctx = util.AddIntervalToContext(ctx, ig.Interval())
+		case <-ig.stopCh:
+			return
+		case <-ig.wakeCh:
+			ig.Reset()
+		case now := <-timer.C():
+			due, records := ig.popDue(now)
+			if len(due) > 0 {
+				if ig.store != nil {
+					if err := ig.store.Save(ctx, records...); err != nil {
+						logger.ErrorContext(ctx, "Failed to persist fire times", slog.Any("error", err))
+					}
+				}
 
-			if err := ig.handler(ctx); err != nil {
-				logger.ErrorContext(ctx, "Failed to handle interval group", slog.Any("error", err))
+				ig.dispatch(ctx, due)
 			}
 
 			ig.Reset() // Reset after handling
@@ -107,10 +369,7 @@ func (ig *Scheduler) Stop() error {
 	ig.mu.Lock()
 	defer ig.mu.Unlock()
 
-	if !ig.running {
-		return nil
-	}
-
+	// close stopCh even when the loop has not started yet, so it exits right away
 	ig.running = false
 	ig.stopOnce.Do(func() {
 		close(ig.stopCh)
@@ -118,11 +377,229 @@ func (ig *Scheduler) Stop() error {
 
 	if ig.timer != nil {
 		ig.timer.Stop()
-		// Drain timer channel to avoid goroutine leaks.
-		select {
-		case <-ig.timer.C:
-		default:
-		}
 	}
 	return nil
 }
+
+// popDue reschedules every guild whose fire time has passed and returns their
+// IDs together with their updated records. When staggering, due guilds are
+// first pushed back by a random offset and returned once that has passed.
+func (ig *Scheduler) popDue(now time.Time) ([]snowflake.ID, []Record) {
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+
+	due := make([]snowflake.ID, 0)
+	records := make([]Record, 0)
+	for e := ig.queue.peek(); e != nil && !e.next.After(now); e = ig.queue.peek() {
+		if ig.stagger > 0 && e.deferred.IsZero() {
+			if staggered := ig.staggerEntry(e, now); staggered {
+				records = append(records, e.record(ig.key))
+				continue
+			}
+		}
+
+		fired := e.next
+		if !e.deferred.IsZero() {
+			fired, e.deferred = e.deferred, time.Time{}
+		}
+
+		due = append(due, e.guildID)
+		e.last = now
+		e.next = e.timing.Next(fired)
+		if !e.next.After(now) {
+			e.next = e.timing.Next(now) // caught up after downtime, continue from now
+		}
+		if e.next.IsZero() { // timing will never fire again
+			heap.Remove(&ig.queue, e.index)
+			delete(ig.entries, e.guildID)
+			continue
+		}
+		heap.Fix(&ig.queue, e.index)
+		records = append(records, e.record(ig.key))
+	}
+
+	return due, records
+}
+
+// staggerEntry pushes a due guild back by a random offset within the first
+// share of the time until its next fire, it reports whether the guild was
+// pushed past now. Must be called with the lock held.
+func (ig *Scheduler) staggerEntry(e *entry, now time.Time) bool {
+	following := e.timing.Next(e.next)
+	if following.IsZero() {
+		return false
+	}
+
+	window := time.Duration(float64(following.Sub(e.next)) * ig.stagger)
+	if window < time.Millisecond {
+		return false
+	}
+
+	offset := time.Duration(util.RandomInt(0, int(window/time.Millisecond))) * time.Millisecond
+	e.deferred = e.next
+	e.next = e.next.Add(offset)
+	heap.Fix(&ig.queue, e.index)
+
+	return e.next.After(now)
+}
+
+// dispatch runs the handler for the due guilds. Guilds whose previous tick is
+// still being handled follow the overlap policy, the other guilds run right
+// away next to the running handlers.
+func (ig *Scheduler) dispatch(ctx context.Context, due []snowflake.ID) {
+	logger := logging.FromContext(ctx)
+
+	ig.mu.Lock()
+	ready, busy := due, []snowflake.ID(nil)
+	if ig.overlap != OverlapConcurrent {
+		ready = make([]snowflake.ID, 0, len(due))
+		for _, guildID := range due {
+			if ig.busy[guildID] > 0 {
+				busy = append(busy, guildID)
+			} else {
+				ready = append(ready, guildID)
+			}
+		}
+	}
+
+	if len(busy) > 0 {
+		switch ig.overlap {
+		case OverlapSkip:
+			ig.skipped += len(busy)
+		case OverlapQueue:
+			for _, guildID := range busy {
+				ig.pending[guildID] = struct{}{}
+			}
+		}
+	}
+
+	if len(ready) > 0 {
+		ig.claim(ready)
+		ig.inflight++
+		ig.wg.Add(1)
+	}
+	ig.mu.Unlock()
+
+	if len(busy) > 0 {
+		switch ig.overlap {
+		case OverlapSkip:
+			logger.WarnContext(ctx, "Skipping guilds, their previous tick is still running", slog.String("scheduler", ig.key), slog.Int("guilds", len(busy)))
+			if ig.events != nil {
+				now := ig.clock.Now()
+				for _, guildID := range busy {
+					ig.events.Publish(ctx, GuildSkipped{Key: ig.key, GuildID: guildID, Reason: SkipOverlap, At: now})
+				}
+			}
+		case OverlapQueue:
+			logger.DebugContext(ctx, "Queueing guilds behind their running tick", slog.String("scheduler", ig.key), slog.Int("guilds", len(busy)))
+		}
+	}
+
+	if len(ready) > 0 {
+		go ig.run(ctx, ready)
+	}
+}
+
+// run handles the due guilds, followed by the queued guilds whose previous
+// tick has finished in the meantime.
+func (ig *Scheduler) run(ctx context.Context, due []snowflake.ID) {
+	defer ig.wg.Done()
+
+	for len(due) > 0 {
+		ig.handle(ctx, due)
+
+		ig.mu.Lock()
+		ig.release(due)
+		due = nil
+		if ctx.Err() != nil {
+			clear(ig.pending)
+		}
+		for guildID := range ig.pending {
+			if ig.busy[guildID] == 0 {
+				due = append(due, guildID)
+				delete(ig.pending, guildID)
+			}
+		}
+		if len(due) == 0 {
+			ig.inflight--
+		} else {
+			ig.claim(due)
+		}
+		ig.mu.Unlock()
+	}
+}
+
+// claim marks the guilds as being handled. Must be called with the lock held.
+func (ig *Scheduler) claim(guildIDs []snowflake.ID) {
+	for _, guildID := range guildIDs {
+		ig.busy[guildID]++
+	}
+}
+
+// release marks the guilds as no longer being handled. Must be called with the
+// lock held.
+func (ig *Scheduler) release(guildIDs []snowflake.ID) {
+	for _, guildID := range guildIDs {
+		if ig.busy[guildID]--; ig.busy[guildID] <= 0 {
+			delete(ig.busy, guildID)
+		}
+	}
+}
+
+// handle calls the handler once, cancelling its context after the timeout.
+func (ig *Scheduler) handle(ctx context.Context, due []snowflake.ID) {
+	logger := logging.FromContext(ctx)
+
+	tickCtx := util.AddIntervalToContext(ctx, ig.Interval())
+	tickCtx = util.AddGuildIDsToContext(tickCtx, due)
+	tickCtx = util.AddSchedulerKeyToContext(tickCtx, ig.key)
+	tickCtx = addClockToContext(tickCtx, ig.clock)
+	if ig.events != nil {
+		tickCtx = addBusToContext(tickCtx, ig.events)
+	}
+
+	handler := ig.handler
+	if ig.timeout > 0 {
+		handler = Timeout(ig.timeout, ig.clock)(handler) // outermost, the timeout covers the middlewares
+	}
+
+	started := ig.clock.Now()
+	Publish(tickCtx, TickStarted{Key: ig.key, Guilds: slices.Clone(due), At: started})
+
+	err := handler(tickCtx)
+	timedOut := errors.Is(err, ErrHandlerTimeout)
+	ig.recordRun(started, ig.clock.Now().Sub(started), err, timedOut)
+
+	if timedOut {
+		logger.WarnContext(ctx, "Handler timed out", slog.String("scheduler", ig.key), slog.Duration("timeout", ig.timeout), slog.Int("guilds", len(due)))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to handle interval group", slog.Any("error", err))
+	}
+}
+
+// recordRun keeps the statistics of a handler run for Snapshot.
+func (ig *Scheduler) recordRun(started time.Time, duration time.Duration, err error, timedOut bool) {
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+
+	ig.lastRun = started
+	ig.lastDuration = duration
+	ig.lastErr = err
+	ig.runs++
+	if err != nil {
+		ig.failures++
+	}
+	if timedOut {
+		ig.timedOut++
+	}
+}
+
+// wake notifies the running loop that the earliest fire time may have changed.
+// Must be called with the lock held.
+func (ig *Scheduler) wake() {
+	select {
+	case ig.wakeCh <- struct{}{}:
+	default:
+	}
+}
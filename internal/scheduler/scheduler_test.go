@@ -0,0 +1,310 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+var epoch = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+// fixed fires exactly d after the last fire, so tests know every fire time.
+type fixed time.Duration
+
+func (f fixed) Next(last time.Time) time.Time { return last.Add(time.Duration(f)) }
+
+func (f fixed) Interval() time.Duration { return time.Duration(f) }
+
+func (f fixed) String() string { return "fixed " + time.Duration(f).String() }
+
+// recorder returns a handler sending the due guilds of every tick on the returned channel.
+func recorder() (HandleFunc, chan []snowflake.ID) {
+	ch := make(chan []snowflake.ID, 16)
+	return func(ctx context.Context) error {
+		guildIDs, _ := util.GetGuildIDsFromContext(ctx)
+		guildIDs = slices.Clone(guildIDs)
+		slices.Sort(guildIDs)
+		ch <- guildIDs
+		return nil
+	}, ch
+}
+
+func receive(t *testing.T, ch chan []snowflake.ID) []snowflake.ID {
+	t.Helper()
+
+	select {
+	case guildIDs := <-ch:
+		return guildIDs
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not run")
+		return nil
+	}
+}
+
+func noTick(t *testing.T, ch chan []snowflake.ID) {
+	t.Helper()
+
+	select {
+	case guildIDs := <-ch:
+		t.Fatalf("handler ran for %v, want no tick", guildIDs)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// eventually waits for cond to hold, the scheduler loop reacts in its own goroutine.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// start runs the scheduler until the test ends and waits for its timer to be armed.
+func start(t *testing.T, s *Scheduler, clock *FakeClock) <-chan struct{} {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Start(context.Background())
+	}()
+	t.Cleanup(func() {
+		_ = s.Stop()
+		<-done
+	})
+
+	clock.BlockUntil(1)
+	return done
+}
+
+func TestSchedulerFiresDueGuilds(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks := recorder()
+
+	s := NewScheduler(time.Minute, handler, WithSchedulerClock(clock))
+	s.Add(1, fixed(time.Minute))
+	s.Add(2, fixed(2*time.Minute))
+	start(t, s, clock)
+
+	if got := s.NextInterval(); !got.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("next interval is %s, want %s", got, epoch.Add(time.Minute))
+	}
+
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1}) {
+		t.Fatalf("first tick handled %v, want [1]", got)
+	}
+
+	clock.BlockUntil(1)
+	if got := s.NextInterval(); !got.Equal(epoch.Add(2 * time.Minute)) {
+		t.Fatalf("next interval is %s, want %s", got, epoch.Add(2*time.Minute))
+	}
+
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1, 2}) {
+		t.Fatalf("second tick handled %v, want [1 2]", got)
+	}
+
+	next, ok := s.Next(2)
+	if !ok || !next.Equal(epoch.Add(4*time.Minute)) {
+		t.Fatalf("next fire of guild 2 is %s, want %s", next, epoch.Add(4*time.Minute))
+	}
+}
+
+func TestSchedulerResetFollowsTheEarliestGuild(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks := recorder()
+
+	s := NewScheduler(time.Minute, handler, WithSchedulerClock(clock))
+	s.Add(1, fixed(time.Hour))
+	start(t, s, clock)
+
+	// a sooner guild re-arms the timer
+	s.Add(2, fixed(time.Minute))
+	eventually(t, func() bool { return s.NextInterval().Equal(epoch.Add(time.Minute)) })
+	clock.BlockUntil(1)
+
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{2}) {
+		t.Fatalf("tick handled %v, want [2]", got)
+	}
+
+	// without guilds the timer is stopped
+	clock.BlockUntil(1)
+	s.Remove(1)
+	s.Remove(2)
+	eventually(t, func() bool { return clock.Timers() == 0 })
+	if got := s.NextInterval(); !got.IsZero() {
+		t.Fatalf("next interval is %s, want the zero time", got)
+	}
+
+	clock.Advance(2 * time.Hour)
+	noTick(t, ticks)
+}
+
+func TestSchedulerStopCancelsRunningHandlers(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	running, cancelled := make(chan struct{}), make(chan struct{})
+	s := NewScheduler(time.Minute, func(ctx context.Context) error {
+		close(running)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}, WithSchedulerClock(clock))
+	s.Add(1, fixed(time.Minute))
+	done := start(t, s, clock)
+
+	clock.Advance(time.Minute)
+	<-running
+
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Start did not return after Stop")
+	}
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("Start returned before the running handler saw its context cancelled")
+	}
+
+	if s.IsRunning() {
+		t.Fatal("scheduler still reports running after Stop")
+	}
+}
+
+func TestSchedulerHandlerTimeout(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	running := make(chan struct{})
+	s := NewScheduler(time.Minute, func(ctx context.Context) error {
+		close(running)
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}, WithSchedulerClock(clock), WithHandlerTimeout(30*time.Second))
+	s.Add(1, fixed(time.Minute))
+	start(t, s, clock)
+
+	clock.Advance(time.Minute)
+	<-running
+
+	// the timer of the scheduler and the timeout of the handler
+	clock.BlockUntil(2)
+	clock.Advance(30 * time.Second)
+
+	eventually(t, func() bool { return s.Snapshot().TimedOut == 1 })
+	if snapshot := s.Snapshot(); snapshot.Failures != 1 || snapshot.LastError == "" {
+		t.Fatalf("snapshot %+v does not record the timed out run", snapshot)
+	}
+}
+
+// blocking returns a recorder whose handler blocks ticks of guild 1 until release is closed.
+func blocking() (HandleFunc, chan []snowflake.ID, chan struct{}) {
+	record, ticks := recorder()
+	release := make(chan struct{})
+	return func(ctx context.Context) error {
+		_ = record(ctx)
+		if guildIDs, _ := util.GetGuildIDsFromContext(ctx); slices.Contains(guildIDs, 1) {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+		}
+		return nil
+	}, ticks, release
+}
+
+func TestSchedulerOverlapSkipsOnlyBusyGuilds(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks, release := blocking()
+
+	s := NewScheduler(time.Minute, handler, WithSchedulerClock(clock), WithOverlap(OverlapSkip))
+	s.Add(1, fixed(time.Minute))
+	s.Add(2, fixed(2*time.Minute))
+	start(t, s, clock)
+
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1}) {
+		t.Fatalf("first tick handled %v, want [1]", got)
+	}
+
+	// guild 1 is still being handled, guild 2 is not held up by it
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{2}) {
+		t.Fatalf("second tick handled %v, want [2]", got)
+	}
+	eventually(t, func() bool { return s.Snapshot().Skipped == 1 })
+
+	close(release)
+	noTick(t, ticks)
+}
+
+func TestSchedulerOverlapQueuesBusyGuilds(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, ticks, release := blocking()
+
+	s := NewScheduler(time.Minute, handler, WithSchedulerClock(clock), WithOverlap(OverlapQueue))
+	s.Add(1, fixed(time.Minute))
+	s.Add(2, fixed(2*time.Minute))
+	start(t, s, clock)
+
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1}) {
+		t.Fatalf("first tick handled %v, want [1]", got)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{2}) {
+		t.Fatalf("second tick handled %v, want [2]", got)
+	}
+
+	// the queued guild runs once its previous tick finishes
+	close(release)
+	if got := receive(t, ticks); !slices.Equal(got, []snowflake.ID{1}) {
+		t.Fatalf("queued tick handled %v, want [1]", got)
+	}
+	if snapshot := s.Snapshot(); snapshot.Skipped != 0 {
+		t.Fatalf("snapshot %+v skipped guilds, want them queued", snapshot)
+	}
+}
+
+func TestSchedulerPersistsStaggeredFireTimes(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	handler, _ := recorder()
+
+	s := NewScheduler(time.Minute, handler, WithSchedulerClock(clock), WithDispatch(DispatchStagger, 0.5))
+	for guildID := range snowflake.ID(10) {
+		s.Add(guildID, fixed(time.Minute))
+	}
+
+	due, records := s.popDue(epoch.Add(time.Minute))
+	if len(records) != 10 || len(due) == 10 {
+		t.Fatalf("popped %d due guilds and %d records, want staggered guilds", len(due), len(records))
+	}
+	for _, r := range records {
+		if slices.Contains(due, r.GuildID) {
+			continue // its offset happened to be 0
+		}
+		if !r.Deferred.Equal(epoch.Add(time.Minute)) || !r.Next.After(r.Deferred) {
+			t.Fatalf("record %+v does not keep the fire time it was staggered from", r)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Snapshot describes the state of a scheduler at a point in time.
+type Snapshot struct {
+	Key      string        `json:"key"`
+	Interval time.Duration `json:"interval"`
+	Running  bool          `json:"running"`
+	Guilds   int           `json:"guilds"`
+	NextFire time.Time     `json:"next_fire"` // zero when no guilds are scheduled
+
+	LastRun      time.Time     `json:"last_run"` // zero when the handler did not run yet
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	Skipped      int           `json:"skipped"`   // guilds dropped because their previous tick was still running
+	TimedOut     int           `json:"timed_out"` // runs that exceeded the handler timeout
+	InFlight     int           `json:"in_flight"` // handlers running right now
+}
+
+// Snapshot returns the current state of the scheduler.
+func (t *Scheduler) Snapshot() Snapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	s := Snapshot{
+		Key:          t.key,
+		Interval:     t.interval,
+		Running:      t.running,
+		Guilds:       len(t.entries),
+		LastRun:      t.lastRun,
+		LastDuration: t.lastDuration,
+		Runs:         t.runs,
+		Failures:     t.failures,
+		Skipped:      t.skipped,
+		TimedOut:     t.timedOut,
+		InFlight:     t.inflight,
+	}
+	if e := t.queue.peek(); e != nil {
+		s.NextFire = e.next
+	}
+	if t.lastErr != nil {
+		s.LastError = t.lastErr.Error()
+	}
+	return s
+}
+
+// Snapshot returns the state of every scheduler, ordered by key and interval.
+func (m *Manager) Snapshot() []Snapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshots := make([]Snapshot, 0, len(m.schedulers))
+	for _, group := range m.schedulers {
+		snapshots = append(snapshots, group.Snapshot())
+	}
+
+	slices.SortFunc(snapshots, func(a, b Snapshot) int {
+		return cmp.Or(cmp.Compare(a.Key, b.Key), cmp.Compare(a.Interval, b.Interval))
+	})
+	return snapshots
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/uptrace/bun"
+
+	"github.com/XanderD99/disruptor/internal/models"
+)
+
+// Record is the persisted state of a scheduled guild.
+type Record struct {
+	Key      string
+	GuildID  snowflake.ID
+	Next     time.Time
+	Last     time.Time
+	Deferred time.Time // fire time a staggered guild was pushed back from, the fire after Next follows from it
+}
+
+// Store persists fire times so a restart does not reset every guild's countdown.
+type Store interface {
+	Load(ctx context.Context) ([]Record, error)
+	Save(ctx context.Context, records ...Record) error
+	Delete(ctx context.Context, key string, guildID snowflake.ID) error
+}
+
+type dbStore struct {
+	db *bun.DB
+}
+
+// NewStore returns a Store backed by the schedules table.
+func NewStore(db *bun.DB) Store {
+	return dbStore{db: db}
+}
+
+// Load implements Store.
+func (s dbStore) Load(ctx context.Context) ([]Record, error) {
+	schedules := make([]models.Schedule, 0)
+	if err := s.db.NewSelect().Model(&schedules).Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load schedules: %w", err)
+	}
+
+	records := make([]Record, 0, len(schedules))
+	for _, schedule := range schedules {
+		records = append(records, Record{
+			Key:      schedule.Key,
+			GuildID:  schedule.GuildID,
+			Next:     schedule.NextFireAt,
+			Last:     schedule.LastFireAt,
+			Deferred: schedule.DeferredFireAt,
+		})
+	}
+
+	return records, nil
+}
+
+// Save implements Store.
+func (s dbStore) Save(ctx context.Context, records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	schedules := make([]models.Schedule, 0, len(records))
+	for _, record := range records {
+		schedules = append(schedules, models.Schedule{
+			GuildID:        record.GuildID,
+			Key:            record.Key,
+			NextFireAt:     record.Next,
+			LastFireAt:     record.Last,
+			DeferredFireAt: record.Deferred,
+		})
+	}
+
+	if _, err := s.db.NewInsert().Model(&schedules).On("CONFLICT (guild_id, key) DO UPDATE").Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+
+	return nil
+}
+
+// Delete implements Store.
+func (s dbStore) Delete(ctx context.Context, key string, guildID snowflake.ID) error {
+	if _, err := s.db.NewDelete().Model(&models.Schedule{GuildID: guildID, Key: key}).WherePK().Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/XanderD99/disruptor/internal/util"
+)
+
+// Timing decides when a scheduled guild fires.
+type Timing interface {
+	// Next returns the first fire time after last.
+	Next(last time.Time) time.Time
+	// Interval is the nominal interval used to group guilds into schedulers,
+	// timings without a fixed interval return 0.
+	Interval() time.Duration
+	// String describes the timing, equal timings have equal descriptions.
+	String() string
+}
+
+// everyJitter is the share of the interval a fire of Every is moved by at most.
+const everyJitter = 0.25
+
+type every struct {
+	interval time.Duration
+}
+
+// Every fires about once per interval. Each delay is the interval moved by a
+// random jitter of up to a quarter of it, so consecutive fires stay 3/4 to 5/4
+// of the interval apart while guilds sharing an interval drift apart instead
+// of being disrupted at the same instant.
+func Every(interval time.Duration) Timing {
+	return every{interval: interval}
+}
+
+// Next implements Timing.
+func (e every) Next(last time.Time) time.Time {
+	jitter := int(float64(e.interval/time.Millisecond) * everyJitter)
+	offset := time.Duration(util.RandomInt(-jitter, jitter)) * time.Millisecond
+	return last.Add(e.interval + offset)
+}
+
+// Interval implements Timing.
+func (e every) Interval() time.Duration {
+	return e.interval
+}
+
+func (e every) String() string {
+	return "every " + e.interval.String()
+}
+
+// Preview returns the next n fire times of timing, starting with first.
+func Preview(timing Timing, first time.Time, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for t := first; len(times) < n && !t.IsZero(); t = timing.Next(t) {
+		times = append(times, t)
+	}
+	return times
+}
+
+type uniform struct {
+	min, max time.Duration
+}
+
+// Uniform waits a random delay between min and max after every fire.
+func Uniform(minimum, maximum time.Duration) Timing {
+	return uniform{min: minimum, max: maximum}
+}
+
+// Next implements Timing.
+func (u uniform) Next(last time.Time) time.Time {
+	delay := util.RandomInt(int(u.min/time.Millisecond), int(u.max/time.Millisecond))
+	return last.Add(time.Duration(delay) * time.Millisecond)
+}
+
+// Interval implements Timing, guilds are grouped by the mean delay.
+func (u uniform) Interval() time.Duration {
+	return (u.min + u.max) / 2
+}
+
+func (u uniform) String() string {
+	return fmt.Sprintf("%s-%s", u.min, u.max)
+}
+
+// minExponentialDelay keeps exponential timings from firing back to back.
+const minExponentialDelay = time.Minute
+
+type exponential struct {
+	mean time.Duration
+}
+
+// Exponential draws exponentially distributed delays with the given mean, so
+// fires follow a Poisson process and the time of the next fire can not be
+// guessed from the previous one. Delays are at least a minute.
+func Exponential(mean time.Duration) Timing {
+	return exponential{mean: mean}
+}
+
+// Next implements Timing.
+func (e exponential) Next(last time.Time) time.Time {
+	u := util.RandomFloat(0.000001, 1) // avoid log(0)
+	delay := time.Duration(-math.Log(u) * float64(e.mean))
+	return last.Add(max(delay, minExponentialDelay))
+}
+
+// Interval implements Timing, guilds are grouped by the mean delay.
+func (e exponential) Interval() time.Duration {
+	return e.mean
+}
+
+func (e exponential) String() string {
+	return fmt.Sprintf("~%s", e.mean)
+}
+
+type burst struct {
+	burst Timing
+	until time.Time
+	after Timing
+}
+
+// Burst fires following burst until the given time and following after from
+// then on. The guild keeps the interval of after, so a burst does not leave
+// a scheduler behind once it is over.
+func Burst(timing Timing, until time.Time, after Timing) Timing {
+	return burst{burst: timing, until: until, after: after}
+}
+
+// Next implements Timing.
+func (b burst) Next(last time.Time) time.Time {
+	if !last.Before(b.until) {
+		return b.after.Next(last)
+	}
+
+	if next := b.burst.Next(last); next.Before(b.until) {
+		return next
+	}
+	return b.after.Next(b.until)
+}
+
+// Interval implements Timing, guilds stay grouped with the timing they return to.
+func (b burst) Interval() time.Duration {
+	return b.after.Interval()
+}
+
+func (b burst) String() string {
+	return fmt.Sprintf("%s until %s, then %s", b.burst, b.until.UTC().Format(time.RFC3339), b.after)
+}
+
+// maxWindowLookahead bounds how many closed stretches Within skips while
+// looking for a fire time inside its window.
+const maxWindowLookahead = 1000
+
+type within struct {
+	timing Timing
+	window *Cron
+}
+
+// Within only fires timing in the minutes matching window, so "every hour on
+// weekdays between 18:00 and 23:00" is Every(time.Hour) within
+// "* 18-22 * * mon-fri". Fires that fall outside the window move to a random
+// moment in the first delay of timing after the window opens again.
+func Within(timing Timing, window *Cron) Timing {
+	return within{timing: timing, window: window}
+}
+
+// Next implements Timing.
+func (w within) Next(last time.Time) time.Time {
+	next := w.timing.Next(last)
+	for range maxWindowLookahead {
+		if next.IsZero() || w.window.Matches(next) {
+			return next
+		}
+
+		opens := w.window.Next(next)
+		if opens.IsZero() {
+			return opens
+		}
+
+		first := w.timing.Next(opens)
+		if first.IsZero() {
+			return first
+		}
+		span := int(first.Sub(opens) / time.Millisecond)
+		next = opens.Add(time.Duration(util.RandomInt(0, max(span-1, 0))) * time.Millisecond)
+	}
+	return time.Time{}
+}
+
+// Interval implements Timing, the window does not change the interval guilds are grouped by.
+func (w within) Interval() time.Duration {
+	return w.timing.Interval()
+}
+
+func (w within) String() string {
+	return fmt.Sprintf("%s within %s", w.timing, w.window)
+}
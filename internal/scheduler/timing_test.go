@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEveryKeepsFiresAroundTheInterval(t *testing.T) {
+	interval := time.Hour
+	timing := Every(interval)
+
+	last := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for range 1000 {
+		next := timing.Next(last)
+		if gap := next.Sub(last); gap < interval*3/4 || gap > interval*5/4 {
+			t.Fatalf("gap between fires is %s, want between %s and %s", gap, interval*3/4, interval*5/4)
+		}
+		last = next
+	}
+}
+
+func TestWithinOnlyFiresInsideTheWindow(t *testing.T) {
+	window, err := ParseCron("* 18-22 * * mon-fri", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timing := Within(Every(time.Hour), window)
+
+	last := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) // a saturday
+	for range 200 {
+		next := timing.Next(last)
+		if !next.After(last) {
+			t.Fatalf("next fire %s is not after %s", next, last)
+		}
+		if next.Weekday() == time.Saturday || next.Weekday() == time.Sunday || next.Hour() < 18 || next.Hour() > 22 {
+			t.Fatalf("fire %s is outside of the window", next)
+		}
+		last = next
+	}
+}
+
+func TestCronIntervalIsTheMeanGap(t *testing.T) {
+	tests := map[string]time.Duration{
+		"*/15 * * * *": 15 * time.Minute,
+		"0 * * * *":    time.Hour,
+		"30 9 * * *":   24 * time.Hour,
+	}
+
+	for expr, want := range tests {
+		c, err := ParseCron(expr, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Interval(); got != want {
+			t.Errorf("%s: interval is %s, want %s", expr, got, want)
+		}
+	}
+}
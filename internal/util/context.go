@@ -3,6 +3,8 @@ package util
 import (
 	"context"
 	"time"
+
+	"github.com/disgoorg/snowflake/v2"
 )
 
 type intervalKey struct{}
@@ -15,3 +17,25 @@ func GetIntervalFromContext(ctx context.Context) (time.Duration, bool) {
 	interval, ok := ctx.Value(intervalKey{}).(time.Duration)
 	return interval, ok
 }
+
+type guildIDsKey struct{}
+
+func AddGuildIDsToContext(ctx context.Context, guildIDs []snowflake.ID) context.Context {
+	return context.WithValue(ctx, guildIDsKey{}, guildIDs)
+}
+
+func GetGuildIDsFromContext(ctx context.Context) ([]snowflake.ID, bool) {
+	guildIDs, ok := ctx.Value(guildIDsKey{}).([]snowflake.ID)
+	return guildIDs, ok
+}
+
+type schedulerKey struct{}
+
+func AddSchedulerKeyToContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, schedulerKey{}, key)
+}
+
+func GetSchedulerKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(schedulerKey{}).(string)
+	return key, ok
+}
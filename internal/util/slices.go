@@ -17,6 +17,14 @@ func Random[T any](ss []T) T {
 	return ss[RandomInt(0, len(ss)-1)]
 }
 
+// Shuffle shuffles ss in place.
+func Shuffle[T any](ss []T) {
+	for i := len(ss) - 1; i > 0; i-- {
+		j := RandomInt(0, i)
+		ss[i], ss[j] = ss[j], ss[i]
+	}
+}
+
 func Chunk[T any](slice []T, size int) [][]T {
 	if size <= 0 {
 		return nil
@@ -2,6 +2,7 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -9,10 +10,14 @@ import (
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/voice"
 	"github.com/disgoorg/ffmpeg-audio"
 	"github.com/disgoorg/snowflake/v2"
 )
 
+// ErrNoSounds is returned when a guild has no soundboard sounds to pick from.
+var ErrNoSounds = errors.New("there are no soundboard sounds available")
+
 func HasVoicePermissions(permissions discord.Permissions) bool {
 	return permissions.Has(discord.PermissionSpeak, discord.PermissionConnect, discord.PermissionViewChannel)
 }
@@ -25,14 +30,49 @@ func GetRandomSound(client *bot.Client, guildID snowflake.ID) (discord.Soundboar
 	}
 
 	if len(sounds) == 0 {
-		return discord.SoundboardSound{}, fmt.Errorf("no soundboard sounds available")
+		return discord.SoundboardSound{}, ErrNoSounds
 	}
 
 	index := RandomInt(0, len(sounds)-1)
 	return sounds[index], nil
 }
 
+// GetRandomSounds returns n random soundboard sounds of a guild, sounds only
+// repeat when the guild has fewer than n.
+func GetRandomSounds(client *bot.Client, guildID snowflake.ID, n int) ([]discord.SoundboardSound, error) {
+	sounds := make([]discord.SoundboardSound, 0)
+
+	for sound := range client.Caches.GuildSoundboardSounds(guildID) {
+		sounds = append(sounds, sound)
+	}
+
+	if len(sounds) == 0 {
+		return nil, ErrNoSounds
+	}
+
+	picked := make([]discord.SoundboardSound, 0, n)
+	for len(picked) < n {
+		Shuffle(sounds)
+		picked = append(picked, sounds[:min(len(sounds), n-len(picked))]...)
+	}
+	return picked, nil
+}
+
 func PlaySound(ctx context.Context, client *bot.Client, guildID, channelID snowflake.ID, url string) error {
+	return PlaySounds(ctx, client, guildID, channelID, Sequence{URLs: []string{url}})
+}
+
+// Sequence is a list of sounds played one after the other in one voice connection.
+type Sequence struct {
+	URLs []string
+
+	GapMin, GapMax time.Duration // random silence between two sounds
+	MaxDuration    time.Duration // the sequence is cut off once it plays this long, 0 for no limit
+}
+
+// PlaySounds joins a voice channel and plays the sounds of a sequence, hitting
+// the max duration of the sequence is not an error.
+func PlaySounds(ctx context.Context, client *bot.Client, guildID, channelID snowflake.ID, seq Sequence) error {
 	conn := client.VoiceManager.CreateConn(guildID)
 
 	if err := conn.Open(ctx, channelID, false, true); err != nil {
@@ -43,7 +83,38 @@ func PlaySound(ctx context.Context, client *bot.Client, guildID, channelID snowf
 	defer cancel()
 	defer conn.Close(cleanupCtx)
 
-	rs, err := http.Get(url)
+	playCtx := ctx
+	if seq.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		playCtx, cancel = context.WithTimeout(ctx, seq.MaxDuration)
+		defer cancel()
+	}
+
+	for i, url := range seq.URLs {
+		if i > 0 {
+			if err := sleep(playCtx, randomDuration(seq.GapMin, seq.GapMax)); err != nil {
+				break
+			}
+		}
+
+		if err := playURL(playCtx, conn, url); err != nil {
+			if playCtx.Err() != nil && ctx.Err() == nil {
+				break // cut off at the max duration
+			}
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
+
+func playURL(ctx context.Context, conn voice.Conn, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error opening sound URL: %w", err)
+	}
+
+	rs, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("error opening sound URL: %w", err)
 	}
@@ -61,6 +132,25 @@ func PlaySound(ctx context.Context, client *bot.Client, guildID, channelID snowf
 	return nil
 }
 
+func randomDuration(minimum, maximum time.Duration) time.Duration {
+	if maximum <= minimum {
+		return minimum
+	}
+	return minimum + time.Duration(RandomInt(0, int((maximum-minimum)/time.Millisecond)))*time.Millisecond
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func ProcessWithWorkerPool[T any](
 	ctx context.Context,
 	items []T,
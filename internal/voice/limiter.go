@@ -0,0 +1,208 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/XanderD99/disruptor/internal/scheduler"
+)
+
+var (
+	// ErrSaturated is returned when the voice budget has no room for another connection.
+	ErrSaturated = errors.New("voice connection budget exhausted")
+	// ErrGuildBusy is returned when a sound is playing in the guild already.
+	ErrGuildBusy = errors.New("a sound is playing in the guild already")
+)
+
+type Config struct {
+	// 🎟️ Voice channel joins allowed per second across all guilds (0 for no limit)
+	JoinsPerSecond float64 `env:"JOINS_PER_SECOND" default:"2"`
+	// 🪣 Joins allowed at once before the joins per second apply
+	JoinBurst int `env:"JOIN_BURST" default:"5"`
+	// 🔊 Maximum voice connections open at the same time (0 for no limit)
+	MaxConnections int `env:"MAX_CONNECTIONS" default:"10"`
+	// ⏳ How long scheduled disruptions wait for room in the voice budget, 0 drops them right away
+	MaxWait time.Duration `env:"MAX_WAIT" default:"1m"`
+}
+
+// Limiter is the voice connection budget of the process, shared by the
+// schedulers and the commands. It limits how fast voice channels are joined
+// with a token bucket and how many connections are open at the same time, and
+// keeps a guild to one connection at a time.
+type Limiter struct {
+	rate    float64
+	burst   float64
+	maxWait time.Duration
+	slots   chan struct{} // nil when connections are not limited
+	clock   scheduler.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	guilds map[snowflake.ID]struct{} // guilds locked by LockGuild
+}
+
+// WithClock sets the clock the join tokens refill on and waits are timed with.
+func WithClock(clock scheduler.Clock) scheduler.Option[Limiter] {
+	return func(l *Limiter) {
+		l.clock = clock
+	}
+}
+
+func NewLimiter(cfg Config, opts ...scheduler.Option[Limiter]) *Limiter {
+	l := &Limiter{
+		rate:    cfg.JoinsPerSecond,
+		burst:   float64(max(cfg.JoinBurst, 1)),
+		maxWait: cfg.MaxWait,
+		clock:   scheduler.SystemClock(),
+		guilds:  make(map[snowflake.ID]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.tokens = l.burst
+	l.last = l.clock.Now()
+
+	if cfg.MaxConnections > 0 {
+		l.slots = make(chan struct{}, cfg.MaxConnections)
+	}
+
+	return l
+}
+
+// Acquire waits up to the configured max wait for a free connection and a
+// join token, it returns ErrSaturated when there was no room in time. The
+// returned release must be called once the connection is closed.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	if l.maxWait <= 0 {
+		return l.TryAcquire()
+	}
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	timer := l.clock.AfterFunc(l.maxWait, cancel)
+	defer timer.Stop()
+
+	release, err := l.wait(waitCtx)
+	if err != nil && ctx.Err() == nil {
+		return nil, ErrSaturated // waited the max wait
+	}
+	return release, err
+}
+
+// TryAcquire takes a free connection and a join token without waiting, it
+// returns ErrSaturated when there is none.
+func (l *Limiter) TryAcquire() (func(), error) {
+	if !l.takeSlot() {
+		return nil, ErrSaturated
+	}
+
+	if _, ok := l.reserve(); !ok {
+		l.releaseSlot()
+		return nil, ErrSaturated
+	}
+
+	return sync.OnceFunc(l.releaseSlot), nil
+}
+
+type guildKey struct{}
+
+// LockGuild reserves the voice connection of a guild, there is only one per
+// guild. It reports false when another caller holds the guild. The returned
+// ctx holds the guild, locking it again with that ctx succeeds right away, so
+// a caller can hold the guild while it decides whether to play. The returned
+// unlock must be called once the connection is closed.
+func (l *Limiter) LockGuild(ctx context.Context, guildID snowflake.ID) (context.Context, func(), bool) {
+	if held, ok := ctx.Value(guildKey{}).(snowflake.ID); ok && held == guildID {
+		return ctx, func() {}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, busy := l.guilds[guildID]; busy {
+		return ctx, nil, false
+	}
+	l.guilds[guildID] = struct{}{}
+
+	unlock := sync.OnceFunc(func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.guilds, guildID)
+	})
+	return context.WithValue(ctx, guildKey{}, guildID), unlock, true
+}
+
+func (l *Limiter) wait(ctx context.Context) (func(), error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for {
+		delay, ok := l.reserve()
+		if ok {
+			return sync.OnceFunc(l.releaseSlot), nil
+		}
+
+		timer := l.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			l.releaseSlot()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// reserve takes a join token, when there is none it returns how long it takes for one to come free.
+func (l *Limiter) reserve() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0, true // joins are not limited
+	}
+
+	now := l.clock.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second)), false
+}
+
+func (l *Limiter) takeSlot() bool {
+	if l.slots == nil {
+		return true
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *Limiter) releaseSlot() {
+	if l.slots == nil {
+		return
+	}
+	<-l.slots
+}
@@ -0,0 +1,175 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/XanderD99/disruptor/internal/scheduler"
+)
+
+var epoch = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestLimiter(cfg Config) (*Limiter, *scheduler.FakeClock) {
+	clock := scheduler.NewFakeClock(epoch)
+	return NewLimiter(cfg, WithClock(clock)), clock
+}
+
+// acquireAll takes join tokens until the limiter refuses one and returns how many it took.
+func acquireAll(t *testing.T, l *Limiter) int {
+	t.Helper()
+
+	for n := 0; ; n++ {
+		if _, err := l.TryAcquire(); err != nil {
+			if !errors.Is(err, ErrSaturated) {
+				t.Fatalf("try acquire returned %v, want %v", err, ErrSaturated)
+			}
+			return n
+		}
+	}
+}
+
+func TestTryAcquireRefillsAtTheJoinRate(t *testing.T) {
+	l, clock := newTestLimiter(Config{JoinsPerSecond: 2, JoinBurst: 3})
+
+	if n := acquireAll(t, l); n != 3 {
+		t.Fatalf("took %d joins at once, want the burst of 3", n)
+	}
+
+	clock.Advance(time.Second)
+	if n := acquireAll(t, l); n != 2 {
+		t.Fatalf("took %d joins after a second, want 2", n)
+	}
+
+	// the bucket does not fill past the burst
+	clock.Advance(time.Hour)
+	if n := acquireAll(t, l); n != 3 {
+		t.Fatalf("took %d joins after an hour, want the burst of 3", n)
+	}
+}
+
+func TestTryAcquireKeepsToTheConnectionCap(t *testing.T) {
+	l, _ := newTestLimiter(Config{MaxConnections: 2})
+
+	releases := make([]func(), 0, 2)
+	for range 2 {
+		release, err := l.TryAcquire()
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+
+	if _, err := l.TryAcquire(); !errors.Is(err, ErrSaturated) {
+		t.Fatalf("try acquire returned %v over the cap, want %v", err, ErrSaturated)
+	}
+
+	releases[0]()
+	releases[0]() // releasing twice frees one connection
+	if _, err := l.TryAcquire(); err != nil {
+		t.Fatalf("try acquire returned %v after a release, want a connection", err)
+	}
+	if _, err := l.TryAcquire(); !errors.Is(err, ErrSaturated) {
+		t.Fatalf("try acquire returned %v over the cap, want %v", err, ErrSaturated)
+	}
+}
+
+func TestAcquireWaitsForAJoinToken(t *testing.T) {
+	l, clock := newTestLimiter(Config{JoinsPerSecond: 2, JoinBurst: 1, MaxWait: time.Minute})
+	if _, err := l.TryAcquire(); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(context.Background())
+		errs <- err
+	}()
+
+	clock.BlockUntil(2) // the max wait and the next token
+	clock.Advance(500 * time.Millisecond)
+	if err := <-errs; err != nil {
+		t.Fatalf("acquire returned %v, want the next token", err)
+	}
+}
+
+func TestAcquireGivesUpAfterTheMaxWait(t *testing.T) {
+	l, clock := newTestLimiter(Config{MaxConnections: 1, MaxWait: time.Minute})
+	if _, err := l.TryAcquire(); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(context.Background())
+		errs <- err
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if err := <-errs; !errors.Is(err, ErrSaturated) {
+		t.Fatalf("acquire returned %v, want %v", err, ErrSaturated)
+	}
+}
+
+func TestAcquireStopsWhenCancelled(t *testing.T) {
+	l, clock := newTestLimiter(Config{JoinsPerSecond: 1, JoinBurst: 1, MaxConnections: 2, MaxWait: time.Minute})
+	if _, err := l.TryAcquire(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx)
+		errs <- err
+	}()
+
+	clock.BlockUntil(2) // the max wait and the next token
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire returned %v, want %v", err, context.Canceled)
+	}
+
+	// the connection taken while waiting for a token is given back
+	clock.Advance(time.Second)
+	if _, err := l.TryAcquire(); err != nil {
+		t.Fatalf("try acquire returned %v, want the connection given back", err)
+	}
+}
+
+func TestLockGuildKeepsOneConnectionPerGuild(t *testing.T) {
+	l := NewLimiter(Config{})
+	ctx := context.Background()
+
+	held, unlock, ok := l.LockGuild(ctx, 1)
+	if !ok {
+		t.Fatal("failed to lock a free guild")
+	}
+
+	if _, _, ok := l.LockGuild(ctx, 1); ok {
+		t.Fatal("locked a guild that is held")
+	}
+	_, unlockOther, ok := l.LockGuild(ctx, 2)
+	if !ok {
+		t.Fatal("failed to lock another guild")
+	}
+	unlockOther()
+
+	// the holder locks the guild again without waiting for itself
+	_, unlockAgain, ok := l.LockGuild(held, 1)
+	if !ok {
+		t.Fatal("holder failed to lock its own guild")
+	}
+	unlockAgain()
+	if _, _, ok := l.LockGuild(ctx, 1); ok {
+		t.Fatal("unlocking the nested lock freed the guild")
+	}
+
+	unlock()
+	unlock() // unlocking twice is harmless
+	if _, _, ok := l.LockGuild(ctx, 1); !ok {
+		t.Fatal("failed to lock a guild after it was unlocked")
+	}
+}